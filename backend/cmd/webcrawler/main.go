@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,8 +19,14 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
+	"github.com/UmutAkturk14/web-crawler/backend/internal/blobstore"
+	analyzer "github.com/UmutAkturk14/web-crawler/backend/internal/crawler"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/credentials"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/routes"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/warc"
 	"github.com/gin-contrib/cors"
 )
 
@@ -53,10 +66,108 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.URL{}, &models.BrokenLink{})
+	err = db.AutoMigrate(&models.User{}, &models.URL{}, &models.BrokenLink{}, &models.Issue{},
+		&models.RedirectChain{}, &models.RedirectHop{}, &models.SEOMetadata{},
+		&models.StructuredData{}, &models.Heading{}, &models.Form{}, &models.SecurityReport{},
+		&models.CrawlRun{}, &models.Tag{}, &models.ArchiveRecord{}, &models.CrawlHAR{})
 	if err != nil {
 		log.Fatal("Database migration failed:", err)
 	}
+	if err := analyzer.BackfillURLHosts(db); err != nil {
+		log.Fatal("Failed to backfill URL hosts:", err)
+	}
+
+	// MX lookups for mailto links are opt-in since they hit DNS for every address
+	if os.Getenv("CHECK_MAILTO_MX") == "true" {
+		analyzer.MailtoMXResolver = net.DefaultResolver
+	}
+
+	if v := os.Getenv("CERT_EXPIRY_WARNING_DAYS"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			log.Fatal("Invalid CERT_EXPIRY_WARNING_DAYS:", err)
+		}
+		analyzer.CertExpiryWarningDays = days
+	}
+
+	if v := os.Getenv("LONG_REDIRECT_CHAIN"); v != "" {
+		hops, err := strconv.Atoi(v)
+		if err != nil || hops < 1 {
+			log.Fatal("Invalid LONG_REDIRECT_CHAIN:", v)
+		}
+		linkcheck.LongRedirectChain = hops
+	}
+
+	// Crawls are archived as WARC files once a directory is configured
+	warcDir := os.Getenv("WARC_DIR")
+	if warcDir != "" {
+		warcConfig := warc.Config{Dir: warcDir, Prefix: "crawl", Software: "web-crawler"}
+		if v := os.Getenv("WARC_MAX_SIZE_MB"); v != "" {
+			mb, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || mb <= 0 {
+				log.Fatal("Invalid WARC_MAX_SIZE_MB:", v)
+			}
+			warcConfig.MaxSize = mb << 20
+		}
+		archive, err := warc.NewWriter(warcConfig)
+		if err != nil {
+			log.Fatal("Failed to set up WARC archive:", err)
+		}
+		defer archive.Close()
+		analyzer.Archive = archive
+		analyzer.ArchiveLinkChecks = os.Getenv("WARC_LINK_CHECKS") == "true"
+	}
+
+	// The HTML of each crawl is kept once a snapshot directory is configured
+	var snapshots blobstore.Store
+	if dir := os.Getenv("SNAPSHOT_DIR"); dir != "" {
+		store, err := blobstore.NewFS(dir)
+		if err != nil {
+			log.Fatal("Failed to set up snapshot storage:", err)
+		}
+		snapshots = store
+		analyzer.Snapshots = store
+
+		if v := os.Getenv("SNAPSHOT_KEEP_RUNS"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				log.Fatal("Invalid SNAPSHOT_KEEP_RUNS:", v)
+			}
+			analyzer.SnapshotPolicy.KeepRuns = n
+		}
+		if v := os.Getenv("SNAPSHOT_MAX_AGE_DAYS"); v != "" {
+			days, err := strconv.Atoi(v)
+			if err != nil || days < 0 {
+				log.Fatal("Invalid SNAPSHOT_MAX_AGE_DAYS:", v)
+			}
+			analyzer.SnapshotPolicy.MaxAge = time.Duration(days) * 24 * time.Hour
+		}
+
+		// Snapshots that outlive MaxAge are swept daily
+		go func() {
+			for ; ; time.Sleep(24 * time.Hour) {
+				if err := analyzer.PruneExpiredSnapshots(db); err != nil {
+					log.Printf("Failed to prune expired snapshots: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Crawl credentials can only be stored once an encryption key is configured
+	if key := os.Getenv("CREDENTIALS_KEY"); key != "" {
+		if err := credentials.SetKey(key); err != nil {
+			log.Fatal("Invalid CREDENTIALS_KEY:", err)
+		}
+	}
+
+	fetcherConfig, err := fetcher.ConfigFromEnv()
+	if err != nil {
+		log.Fatal("Invalid crawler configuration:", err)
+	}
+	pageFetcher, err := fetcher.New(fetcherConfig)
+	if err != nil {
+		log.Fatal("Failed to create HTTP fetcher:", err)
+	}
 
 	r := gin.Default()
 
@@ -71,7 +182,33 @@ func main() {
 	}))
 
 	routes.RegisterAuthRoutes(r, db)
-	routes.RegisterURLRoutes(r, db)
+	routes.RegisterURLRoutes(r, db, pageFetcher)
+	routes.RegisterSitemapRoutes(r, db, pageFetcher)
+	routes.RegisterBulkRoutes(r, db, pageFetcher)
+	routes.RegisterExportRoutes(r, db)
+	routes.RegisterArchiveRoutes(r, db, warcDir)
+	routes.RegisterSnapshotRoutes(r, db, snapshots)
 
-	r.Run()
+	// Like gin's Run, but stops on SIGINT or SIGTERM so the deferred cleanup,
+	// such as closing the WARC archive, still happens
+	addr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+	srv := &http.Server{Addr: addr, Handler: r}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to shut down cleanly: %v", err)
+	}
 }
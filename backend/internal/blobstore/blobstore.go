@@ -0,0 +1,37 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrNotFound is returned for keys that aren't in the store
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs under the SHA-256 of their content, so storing the same
+// content twice keeps a single copy. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores data unless it's already present and returns its key
+	Put(ctx context.Context, data []byte) (string, error)
+	// Get returns the blob stored under key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes a blob. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Key is the key data is stored under
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// validKey reports whether key is a key as returned by Key
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
@@ -0,0 +1,129 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFSPutGet(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "html", data: []byte("<html><body>Hello</body></html>")},
+		{name: "empty", data: []byte{}},
+		{name: "binary", data: []byte{0, 1, 2, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := store.Put(ctx, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != Key(tt.data) {
+				t.Errorf("key = %s, want %s", key, Key(tt.data))
+			}
+			if _, err := os.Stat(filepath.Join(dir, key[:2], key)); err != nil {
+				t.Errorf("blob file: %v", err)
+			}
+
+			got, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("Get = %q, want %q", got, tt.data)
+			}
+		})
+	}
+}
+
+func TestFSDedup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("same body")
+	first, err := store.Put(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Put(ctx, append([]byte(nil), data...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := store.Put(ctx, []byte("other body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || first == other {
+		t.Errorf("keys = %s, %s, %s, want the first two equal", first, second, other)
+	}
+
+	var files int
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 2 {
+		t.Errorf("store holds %d files, want 2", files)
+	}
+}
+
+func TestFSDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := store.Put(ctx, []byte("gone soon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing blob: %v", err)
+	}
+}
+
+func TestFSRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "abc", "../../etc/passwd", Key(nil)[:63] + "g"} {
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): err = %v, want an invalid key error", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded", key)
+		}
+	}
+}
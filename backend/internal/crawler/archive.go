@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/warc"
+	"gorm.io/gorm"
+)
+
+// Archive receives every request and response of a crawl as WARC records when set
+var Archive *warc.Writer
+
+// ArchiveLinkChecks also archives the requests made to check the page's links
+var ArchiveLinkChecks bool
+
+// archiveRecorder writes the exchanges of one crawl run to the archive and indexes them
+type archiveRecorder struct {
+	db     *gorm.DB
+	run    *models.CrawlRun
+	source string
+}
+
+// withArchive returns a context whose requests are archived for the run
+func withArchive(ctx context.Context, db *gorm.DB, run *models.CrawlRun, source string) context.Context {
+	if Archive == nil {
+		return ctx
+	}
+	return fetcher.WithExchangeRecorder(ctx, &archiveRecorder{db: db, run: run, source: source})
+}
+
+func (a *archiveRecorder) RecordExchange(ex *fetcher.Exchange) {
+	// Nothing was received for failed requests
+	if ex.Response == nil {
+		return
+	}
+
+	request := warc.NewRequestRecord(ex.Request, ex.Start)
+	response := warc.NewResponseRecord(ex.Response, ex.Body, ex.Start)
+	response.ConcurrentTo = request.ID
+	request.ConcurrentTo = response.ID
+	if host, _, err := net.SplitHostPort(ex.RemoteAddr); err == nil {
+		response.IPAddress = host
+		request.IPAddress = host
+	}
+	// The body was cut at MaxBodySize or not read to the end
+	if ex.Truncated {
+		response.Truncated = "unspecified"
+	}
+
+	locations, err := Archive.Write(request, response)
+	if err != nil {
+		fmt.Println("Failed to archive", ex.Request.URL, ":", err)
+		return
+	}
+	if a.run.ID == 0 {
+		return
+	}
+
+	entries := make([]models.ArchiveRecord, len(locations))
+	for i, record := range []*warc.Record{request, response} {
+		entries[i] = models.ArchiveRecord{
+			CrawlRunID:    a.run.ID,
+			RecordID:      record.ID,
+			Type:          record.Type,
+			Source:        a.source,
+			TargetURI:     record.TargetURI,
+			PayloadDigest: record.PayloadDigest,
+			Truncated:     record.Truncated != "",
+			Filename:      locations[i].Filename,
+			Offset:        locations[i].Offset,
+			Length:        locations[i].Length,
+			Date:          record.Date,
+		}
+	}
+	entries[1].StatusCode = ex.Response.StatusCode
+	if err := a.db.Create(&entries).Error; err != nil {
+		fmt.Println("Failed to index archived records:", err)
+	}
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"golang.org/x/net/html/charset"
+)
+
+// metaCharsetPattern matches both <meta charset="..."> and the charset
+// parameter of <meta http-equiv="Content-Type" content="...">
+var metaCharsetPattern = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.\-]+)`)
+
+// charsetInfo holds every charset declaration found for a page and the one used to decode it
+type charsetInfo struct {
+	Header   string
+	BOM      string
+	Meta     string
+	Detected string
+}
+
+// detectCharset finds the charset of an HTML body. A BOM wins over the
+// Content-Type header, which wins over <meta>; without any declaration valid
+// UTF-8 is assumed, and windows-1252 otherwise like browsers do.
+func detectCharset(contentType string, body []byte) charsetInfo {
+	var info charsetInfo
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		info.Header = canonicalCharset(params["charset"])
+	}
+	info.BOM = bomCharset(body)
+
+	// Browsers only look at the first 1024 bytes for a <meta> declaration
+	head := body
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if match := metaCharsetPattern.FindSubmatch(head); match != nil {
+		info.Meta = canonicalCharset(string(match[1]))
+	}
+
+	switch {
+	case info.BOM != "":
+		info.Detected = info.BOM
+	case info.Header != "":
+		info.Detected = info.Header
+	case info.Meta != "":
+		info.Detected = info.Meta
+	case utf8.Valid(body):
+		info.Detected = "utf-8"
+	default:
+		info.Detected = "windows-1252"
+	}
+
+	return info
+}
+
+// Issues reports conflicting or missing charset declarations
+func (info charsetInfo) Issues(pageURL string) []linkcheck.Issue {
+	if info.Header == "" && info.BOM == "" && info.Meta == "" {
+		return []linkcheck.Issue{{
+			Category: "encoding",
+			Severity: linkcheck.SeverityWarning,
+			Code:     "charset_missing",
+			Message:  fmt.Sprintf("No charset declared, assumed %s", info.Detected),
+			Target:   pageURL,
+		}}
+	}
+
+	var conflicts []string
+	for _, d := range []struct{ source, charset string }{
+		{"BOM", info.BOM},
+		{"Content-Type header", info.Header},
+		{"<meta>", info.Meta},
+	} {
+		if d.charset != "" && d.charset != info.Detected {
+			conflicts = append(conflicts, fmt.Sprintf("%s declares %s", d.source, d.charset))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return []linkcheck.Issue{{
+		Category: "encoding",
+		Severity: linkcheck.SeverityWarning,
+		Code:     "charset_conflict",
+		Message:  fmt.Sprintf("Conflicting charset declarations, decoded as %s but %s", info.Detected, strings.Join(conflicts, ", ")),
+		Target:   pageURL,
+	}}
+}
+
+// decodeToUTF8 transcodes body from the detected charset, dropping any BOM
+func decodeToUTF8(body []byte, info charsetInfo) ([]byte, error) {
+	body = bytes.TrimPrefix(body, bomBytes[info.BOM])
+
+	enc, _ := charset.Lookup(info.Detected)
+	if enc == nil || info.Detected == "utf-8" {
+		return body, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s body: %w", info.Detected, err)
+	}
+	return decoded, nil
+}
+
+var bomBytes = map[string][]byte{
+	"utf-8":    {0xEF, 0xBB, 0xBF},
+	"utf-16be": {0xFE, 0xFF},
+	"utf-16le": {0xFF, 0xFE},
+}
+
+func bomCharset(body []byte) string {
+	for name, bom := range bomBytes {
+		if bytes.HasPrefix(body, bom) {
+			return name
+		}
+	}
+	return ""
+}
+
+// canonicalCharset maps a charset label (latin1, ISO-8859-9, sjis...) to its
+// WHATWG name, so declarations can be compared. Unknown labels are kept as-is.
+func canonicalCharset(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return ""
+	}
+	if _, name := charset.Lookup(label); name != "" {
+		return name
+	}
+	return label
+}
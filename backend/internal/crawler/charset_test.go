@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectCharset(t *testing.T) {
+	utf8BOM := "\xEF\xBB\xBF"
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        charsetInfo
+	}{
+		{
+			name:        "BOM wins over header and meta",
+			contentType: "text/html; charset=iso-8859-1",
+			body:        utf8BOM + `<meta charset="shift_jis"><p>hi</p>`,
+			want:        charsetInfo{BOM: "utf-8", Header: "windows-1252", Meta: "shift_jis", Detected: "utf-8"},
+		},
+		{
+			name:        "header wins over meta",
+			contentType: "text/html; charset=ISO-8859-9",
+			body:        `<meta charset="utf-8"><p>hi</p>`,
+			want:        charsetInfo{Header: "windows-1254", Meta: "utf-8", Detected: "windows-1254"},
+		},
+		{
+			name:        "meta charset",
+			contentType: "text/html",
+			body:        `<meta charset="latin1"><p>hi</p>`,
+			want:        charsetInfo{Meta: "windows-1252", Detected: "windows-1252"},
+		},
+		{
+			name:        "meta http-equiv",
+			contentType: "text/html",
+			body:        `<meta http-equiv="Content-Type" content="text/html; charset=EUC-JP">`,
+			want:        charsetInfo{Meta: "euc-jp", Detected: "euc-jp"},
+		},
+		{
+			name:        "meta after the first 1024 bytes is ignored",
+			contentType: "text/html",
+			body:        strings.Repeat(" ", 1024) + `<meta charset="shift_jis">`,
+			want:        charsetInfo{Detected: "utf-8"},
+		},
+		{
+			name: "undeclared valid UTF-8",
+			body: "<p>café</p>",
+			want: charsetInfo{Detected: "utf-8"},
+		},
+		{
+			name: "undeclared invalid UTF-8 falls back to windows-1252",
+			body: "<p>caf\xe9</p>",
+			want: charsetInfo{Detected: "windows-1252"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCharset(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCharsetIssues(t *testing.T) {
+	tests := []struct {
+		name string
+		info charsetInfo
+		want string
+	}{
+		{name: "nothing declared", info: charsetInfo{Detected: "utf-8"}, want: "charset_missing"},
+		{name: "consistent", info: charsetInfo{Header: "utf-8", Meta: "utf-8", Detected: "utf-8"}},
+		{name: "conflicting", info: charsetInfo{Header: "windows-1252", Meta: "utf-8", Detected: "windows-1252"}, want: "charset_conflict"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if issues := tt.info.Issues("https://example.com/"); len(issues) > 0 {
+				got = issues[0].Code
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	body := []byte("\xEF\xBB\xBF<p>caf\xc3\xa9</p>")
+	decoded, err := decodeToUTF8(body, detectCharset("", body))
+	if err != nil || string(decoded) != "<p>café</p>" {
+		t.Errorf("got %q, %v", decoded, err)
+	}
+
+	body = []byte("<p>caf\xe9</p>")
+	decoded, err = decodeToUTF8(body, detectCharset("text/html; charset=windows-1252", body))
+	if err != nil || string(decoded) != "<p>café</p>" {
+		t.Errorf("got %q, %v", decoded, err)
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+)
+
+// analyzeContent stores the text statistics of the page on both the URL and
+// the current run, and fingerprints its main content
+func analyzeContent(urlEntry *models.URL, run *models.CrawlRun, doc *goquery.Document, htmlSize int, pageURL string) []linkcheck.Issue {
+	stats := linkcheck.AnalyzeContent(doc, htmlSize)
+
+	urlEntry.WordCount = stats.WordCount
+	urlEntry.TextRatio = stats.TextRatio
+	urlEntry.ReadingEase = stats.ReadingEase
+	urlEntry.ReadingGrade = stats.ReadingGrade
+	urlEntry.DetectedLanguage = stats.DetectedLanguage
+
+	run.WordCount = stats.WordCount
+	run.TextRatio = stats.TextRatio
+	run.ReadingEase = stats.ReadingEase
+	run.ReadingGrade = stats.ReadingGrade
+	run.DetectedLanguage = stats.DetectedLanguage
+
+	// Fingerprints only cover the main content so shared headers and footers don't make pages look alike
+	if mainText := linkcheck.ExtractMainText(doc); mainText != "" {
+		urlEntry.ContentHash = linkcheck.ContentHash(mainText)
+		urlEntry.SimHash = linkcheck.SimHash(mainText)
+	} else {
+		urlEntry.ContentHash, urlEntry.SimHash = "", 0
+	}
+
+	fmt.Println("Word count:", stats.WordCount, "text ratio:", stats.TextRatio,
+		"reading ease:", stats.ReadingEase, "language:", stats.DetectedLanguage)
+	return stats.Issues(pageURL)
+}
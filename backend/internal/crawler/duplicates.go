@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultDuplicateDistance is the largest SimHash distance at which two pages count as near-duplicates
+const DefaultDuplicateDistance = 3
+
+// FindDuplicateClusters groups pages of the same host whose fingerprints are
+// at most maxDistance bits apart. Pages without a fingerprint are ignored and
+// clusters of a single page aren't returned.
+func FindDuplicateClusters(urls []models.URL, maxDistance int) []models.DuplicateCluster {
+	byHost := make(map[string][]models.URL)
+	for _, u := range urls {
+		if u.ContentHash == "" {
+			continue
+		}
+		host := u.Host
+		if host == "" {
+			host = models.SiteHost(u.URL)
+		}
+		byHost[host] = append(byHost[host], u)
+	}
+
+	clusters := []models.DuplicateCluster{}
+	for host, pages := range byHost {
+		// Union-find over every pair close enough to each other
+		parent := make([]int, len(pages))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+		for _, bucket := range candidateBuckets(pages, maxDistance) {
+			for a, i := range bucket {
+				for _, j := range bucket[a+1:] {
+					if find(i) == find(j) {
+						continue
+					}
+					if pages[i].ContentHash == pages[j].ContentHash ||
+						linkcheck.HammingDistance(pages[i].SimHash, pages[j].SimHash) <= maxDistance {
+						parent[find(j)] = find(i)
+					}
+				}
+			}
+		}
+
+		groups := make(map[int][]models.URL)
+		for i, page := range pages {
+			root := find(i)
+			groups[root] = append(groups[root], page)
+		}
+		for _, group := range groups {
+			if len(group) < 2 {
+				continue
+			}
+			clusters = append(clusters, newDuplicateCluster(host, group))
+		}
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Pages) != len(clusters[j].Pages) {
+			return len(clusters[i].Pages) > len(clusters[j].Pages)
+		}
+		return clusters[i].Pages[0].ID < clusters[j].Pages[0].ID
+	})
+	return clusters
+}
+
+// candidateBuckets groups the indexes of pages that may be duplicates, so
+// only pages sharing a bucket need to be compared. The fingerprint is split
+// into maxDistance+1 bands: fingerprints at most maxDistance bits apart agree
+// on at least one whole band, so they always share a bucket. Pages with the
+// same content hash share a bucket too.
+func candidateBuckets(pages []models.URL, maxDistance int) [][]int {
+	type bandKey struct {
+		band  int
+		value uint64
+	}
+	bands := min(maxDistance+1, 64)
+	byBand := make(map[bandKey][]int)
+	byHash := make(map[string][]int)
+	for i, page := range pages {
+		byHash[page.ContentHash] = append(byHash[page.ContentHash], i)
+		for band := 0; band < bands; band++ {
+			from, to := band*64/bands, (band+1)*64/bands
+			value := (page.SimHash >> from) & (1<<(to-from) - 1)
+			key := bandKey{band, value}
+			byBand[key] = append(byBand[key], i)
+		}
+	}
+
+	var buckets [][]int
+	for _, bucket := range byHash {
+		if len(bucket) > 1 {
+			buckets = append(buckets, bucket)
+		}
+	}
+	for _, bucket := range byBand {
+		if len(bucket) > 1 {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+func newDuplicateCluster(host string, group []models.URL) models.DuplicateCluster {
+	sort.Slice(group, func(i, j int) bool { return group[i].ID < group[j].ID })
+
+	cluster := models.DuplicateCluster{Host: host, Exact: true}
+	for _, u := range group {
+		cluster.Exact = cluster.Exact && u.ContentHash == group[0].ContentHash
+		cluster.Pages = append(cluster.Pages, models.DuplicatePage{
+			ID:       u.ID,
+			URL:      u.URL,
+			Title:    u.Title,
+			Distance: linkcheck.HammingDistance(u.SimHash, group[0].SimHash),
+		})
+	}
+	return cluster
+}
+
+// BackfillURLHosts sets the host of URLs stored before it was recorded
+func BackfillURLHosts(db *gorm.DB) error {
+	var urls []models.URL
+	return db.Select("id", "url").Where("host = ''").
+		FindInBatches(&urls, 500, func(tx *gorm.DB, batch int) error {
+			for _, u := range urls {
+				if err := db.Model(&models.URL{}).Where("id = ?", u.ID).
+					Update("host", models.SiteHost(u.URL)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+// duplicateContentIssues flags pages on the same site with exactly the same main text
+func duplicateContentIssues(db *gorm.DB, urlEntry *models.URL) []linkcheck.Issue {
+	if urlEntry.ContentHash == "" {
+		return nil
+	}
+
+	var others []string
+	if err := db.Model(&models.URL{}).
+		Where("content_hash = ? AND host = ? AND id <> ?", urlEntry.ContentHash, models.SiteHost(urlEntry.URL), urlEntry.ID).
+		Limit(5).Pluck("url", &others).Error; err != nil {
+		fmt.Println("Failed to look up duplicate content:", err)
+		return nil
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	return []linkcheck.Issue{{
+		Category: "content",
+		Severity: linkcheck.SeverityWarning,
+		Code:     "content_duplicate",
+		Message:  fmt.Sprintf("Main content is identical to %v", others),
+		Target:   urlEntry.URL,
+	}}
+}
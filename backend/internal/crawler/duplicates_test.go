@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+)
+
+func TestFindDuplicateClusters(t *testing.T) {
+	page := func(id uint, rawURL, hash string, sim uint64) models.URL {
+		return models.URL{ID: id, URL: rawURL, ContentHash: hash, SimHash: sim}
+	}
+	urls := []models.URL{
+		// Exact duplicates, www. and case don't split a site
+		page(1, "https://example.com/a", "h1", 0xFF00),
+		page(2, "https://WWW.example.com/b", "h1", 0xFF00),
+		// Near duplicates of each other within 3 bits, spread across bands
+		page(3, "https://example.com/c", "h3", 0x0F0F_0000_0000_0000),
+		page(4, "https://example.com/d", "h4", 0x0F0F_0000_0000_0007),
+		// Chained through page 4: 3 bits from it, 6 from page 3
+		page(5, "https://example.com/e", "h5", 0x0F0F_0000_0000_003F),
+		// Same fingerprint on another site
+		page(6, "https://other.example/a", "h1", 0xFF00),
+		// Not fingerprinted
+		page(7, "https://example.com/f", "", 0xFF00),
+		// Far from everything
+		page(8, "https://example.com/g", "h8", 0xFFFF_FFFF_FFFF_FFFF),
+	}
+
+	clusters := FindDuplicateClusters(urls, 3)
+
+	var got [][]uint
+	for _, c := range clusters {
+		var ids []uint
+		for _, p := range c.Pages {
+			ids = append(ids, p.ID)
+		}
+		got = append(got, ids)
+	}
+	if want := [][]uint{{3, 4, 5}, {1, 2}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("clusters = %v, want %v", got, want)
+	}
+
+	if clusters[0].Exact || !clusters[1].Exact {
+		t.Errorf("exact = %v, %v, want false, true", clusters[0].Exact, clusters[1].Exact)
+	}
+	if clusters[1].Host != "example.com" {
+		t.Errorf("host = %q, want example.com", clusters[1].Host)
+	}
+	if d := clusters[0].Pages[2].Distance; d != 6 {
+		t.Errorf("distance of page 5 to page 3 = %d, want 6", d)
+	}
+}
+
+// Pages within maxDistance always share a band, wherever the differing bits are
+func TestCandidateBuckets(t *testing.T) {
+	for maxDistance := 0; maxDistance <= 16; maxDistance++ {
+		for shift := 0; shift+maxDistance <= 64; shift += 7 {
+			var diff uint64
+			for bit := 0; bit < maxDistance; bit++ {
+				diff |= 1 << (shift + bit)
+			}
+			pages := []models.URL{
+				{ContentHash: "a", SimHash: 0x1234_5678_9ABC_DEF0},
+				{ContentHash: "b", SimHash: 0x1234_5678_9ABC_DEF0 ^ diff},
+			}
+			if len(candidateBuckets(pages, maxDistance)) == 0 {
+				t.Errorf("maxDistance %d, bits %d-%d: pages share no bucket", maxDistance, shift, shift+maxDistance-1)
+			}
+		}
+	}
+}
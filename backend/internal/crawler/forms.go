@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func formsToModels(forms []linkcheck.Form) []models.Form {
+	result := make([]models.Form, len(forms))
+	for i, f := range forms {
+		result[i] = models.Form{
+			Position:          i + 1,
+			Kind:              f.Kind,
+			Method:            f.Method,
+			Action:            f.Action,
+			ActionHTTPS:       f.ActionHTTPS,
+			HasCSRFToken:      f.HasCSRFToken,
+			Autocomplete:      f.Autocomplete,
+			FieldAutocomplete: f.FieldAutocomplete,
+			PasswordFields:    f.PasswordFields,
+			Selector:          f.Selector,
+		}
+	}
+	return result
+}
+
+// saveForms replaces the forms stored for a URL
+func saveForms(db *gorm.DB, urlEntry *models.URL) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.Form{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old forms: %w", err)
+	}
+	if len(urlEntry.Forms) == 0 {
+		return nil
+	}
+
+	for i := range urlEntry.Forms {
+		urlEntry.Forms[i].URLID = urlEntry.ID
+	}
+	if err := db.Create(&urlEntry.Forms).Error; err != nil {
+		return fmt.Errorf("failed to create forms: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/har"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// harCreator names the crawler in HAR logs
+var harCreator = har.Creator{Name: "web-crawler", Version: "1.0"}
+
+// saveHAR stores the requests recorded during a run as a gzipped HAR log
+func saveHAR(db *gorm.DB, run *models.CrawlRun, urlEntry *models.URL, recorder *har.Recorder) {
+	if run.ID == 0 {
+		return
+	}
+	log := recorder.HAR(harCreator, har.Page{StartedDateTime: run.StartedAt, Title: urlEntry.URL})
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(log); err != nil {
+		fmt.Println("Failed to encode HAR log:", err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		fmt.Println("Failed to compress HAR log:", err)
+		return
+	}
+
+	if err := db.Create(&models.CrawlHAR{CrawlRunID: run.ID, Data: buf.Bytes()}).Error; err != nil {
+		fmt.Println("Failed to save HAR log:", err)
+	}
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func headingsToModels(headings []linkcheck.Heading) []models.Heading {
+	outline := make([]models.Heading, len(headings))
+	for i, h := range headings {
+		outline[i] = models.Heading{
+			Position: i + 1,
+			Level:    h.Level,
+			Text:     h.Text,
+		}
+	}
+	return outline
+}
+
+// saveHeadings replaces the heading outline stored for a URL
+func saveHeadings(db *gorm.DB, urlEntry *models.URL) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.Heading{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old headings: %w", err)
+	}
+	if len(urlEntry.Headings) == 0 {
+		return nil
+	}
+
+	for i := range urlEntry.Headings {
+		urlEntry.Headings[i].URLID = urlEntry.ID
+	}
+	if err := db.Create(&urlEntry.Headings).Error; err != nil {
+		return fmt.Errorf("failed to create headings: %w", err)
+	}
+	return nil
+}
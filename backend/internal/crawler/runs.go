@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// startRun records the start of a crawl. A run that fails to save here is
+// created when it finishes instead.
+func startRun(db *gorm.DB, urlEntry *models.URL) *models.CrawlRun {
+	run := &models.CrawlRun{URLID: urlEntry.ID, Status: "running", StartedAt: time.Now()}
+	if err := db.Create(run).Error; err != nil {
+		fmt.Println("Failed to create crawl run:", err)
+	}
+	return run
+}
+
+// finishRun stores the outcome and page timings of a crawl
+func finishRun(db *gorm.DB, run *models.CrawlRun, metrics *fetcher.Metrics, urlEntry *models.URL, crawlErr error) {
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.HTTPStatusCode = urlEntry.HTTPStatusCode
+	run.Status = "done"
+	if crawlErr != nil {
+		run.Status = "failed"
+		run.FailureReason = crawlErr.Error()
+	}
+
+	// metrics is nil when the crawl failed before the page was requested
+	if metrics != nil {
+		phases := metrics.Phases()
+		run.DNSMs = phases.DNS.Milliseconds()
+		run.ConnectMs = phases.Connect.Milliseconds()
+		run.TLSMs = phases.TLS.Milliseconds()
+		run.TTFBMs = phases.TTFB.Milliseconds()
+		run.DownloadMs = phases.Download.Milliseconds()
+		run.TotalMs = phases.Total.Milliseconds()
+		run.HTMLSize = metrics.BodySize
+		run.TransferSize = metrics.TransferSize
+		run.ContentEncoding = metrics.ContentEncoding
+	}
+
+	if err := db.Save(run).Error; err != nil {
+		fmt.Println("Failed to save crawl run:", err)
+		return
+	}
+	pruneSnapshots(db, run.URLID)
+}
+
+// addResourceWeights counts the scripts, stylesheets and images the page references
+// and adds up their sizes
+func addResourceWeights(run *models.CrawlRun, results []linkcheck.LinkCheckResult) {
+	for _, res := range results {
+		size := res.ContentLength
+		if size < 0 {
+			size = 0
+		}
+		switch res.Type {
+		case linkcheck.ResourceScript:
+			run.ScriptCount++
+			run.ScriptBytes += size
+		case linkcheck.ResourceStylesheet:
+			run.StylesheetCount++
+			run.StylesheetBytes += size
+		case linkcheck.ResourceImage:
+			run.ImageCount++
+			run.ImageBytes += size
+		}
+	}
+}
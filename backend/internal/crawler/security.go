@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// CertExpiryWarningDays is how close to its expiry a certificate gets flagged
+var CertExpiryWarningDays = 30
+
+// Security header grades
+const (
+	headerGood    = "good"
+	headerWeak    = "weak"
+	headerMissing = "missing"
+)
+
+// minHSTSMaxAge is the shortest max-age, 180 days, that isn't considered weak
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// analyzeSecurity grades the security headers of the page response and records
+// its cookie flags and TLS details
+func analyzeSecurity(resp *http.Response, pageURL string) (*models.SecurityReport, []linkcheck.Issue) {
+	var issues []linkcheck.Issue
+	add := func(severity, code, message string) {
+		issues = append(issues, linkcheck.Issue{Category: "security", Severity: severity, Code: code, Message: message, Target: pageURL})
+	}
+
+	isHTTPS := resp.Request.URL.Scheme == "https"
+	report := &models.SecurityReport{
+		Headers: gradeSecurityHeaders(resp.Header, isHTTPS),
+	}
+
+	for _, h := range report.Headers {
+		switch {
+		case h.Grade == headerWeak:
+			add(linkcheck.SeverityWarning, "security_header_weak", fmt.Sprintf("%s is weak: %s", h.Name, h.Note))
+		case h.Grade == headerMissing && isHTTPS:
+			// Browsers default to a safe referrer policy and few sites need Permissions-Policy
+			severity := linkcheck.SeverityWarning
+			if h.Name == "Referrer-Policy" || h.Name == "Permissions-Policy" {
+				severity = linkcheck.SeverityInfo
+			}
+			add(severity, "security_header_missing", fmt.Sprintf("%s header is missing", h.Name))
+		}
+	}
+
+	for _, c := range resp.Cookies() {
+		flags := models.CookieFlags{Name: c.Name, Secure: c.Secure, HttpOnly: c.HttpOnly, SameSite: sameSiteName(c.SameSite)}
+		report.Cookies = append(report.Cookies, flags)
+
+		if isHTTPS && !flags.Secure {
+			add(linkcheck.SeverityWarning, "cookie_not_secure", fmt.Sprintf("Cookie %s is set without the Secure flag", c.Name))
+		}
+		if !flags.HttpOnly {
+			add(linkcheck.SeverityInfo, "cookie_not_httponly", fmt.Sprintf("Cookie %s is readable from JavaScript", c.Name))
+		}
+		if flags.SameSite == "" {
+			add(linkcheck.SeverityInfo, "cookie_samesite_missing", fmt.Sprintf("Cookie %s has no SameSite attribute", c.Name))
+		}
+		if flags.SameSite == "None" && !flags.Secure {
+			add(linkcheck.SeverityWarning, "cookie_samesite_none_insecure", fmt.Sprintf("Cookie %s uses SameSite=None without Secure and is rejected by browsers", c.Name))
+		}
+	}
+
+	if !isHTTPS {
+		add(linkcheck.SeverityWarning, "page_not_https", "Page is served over plain HTTP")
+	}
+	if resp.TLS != nil {
+		issues = append(issues, applyTLSDetails(report, resp.TLS, pageURL, time.Now())...)
+	}
+
+	report.Grade = securityGrade(report.Headers, isHTTPS)
+	return report, issues
+}
+
+// gradeSecurityHeaders grades HSTS, CSP, X-Frame-Options, X-Content-Type-Options,
+// Referrer-Policy and Permissions-Policy
+func gradeSecurityHeaders(header http.Header, isHTTPS bool) []models.SecurityHeader {
+	csp := parseCSP(header.Get("Content-Security-Policy"))
+
+	grade := func(name string, check func(value string) (string, string)) models.SecurityHeader {
+		h := models.SecurityHeader{Name: name, Value: strings.TrimSpace(header.Get(name))}
+		h.Grade, h.Note = check(h.Value)
+		return h
+	}
+
+	return []models.SecurityHeader{
+		grade("Strict-Transport-Security", func(v string) (string, string) {
+			switch {
+			case !isHTTPS:
+				return headerMissing, "HSTS only applies to HTTPS pages"
+			case v == "":
+				return headerMissing, ""
+			}
+			maxAge := -1
+			for _, directive := range strings.Split(v, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+				if strings.EqualFold(name, "max-age") {
+					maxAge, _ = strconv.Atoi(strings.Trim(value, `"`))
+				}
+			}
+			if maxAge < minHSTSMaxAge {
+				return headerWeak, "max-age is shorter than 180 days"
+			}
+			return headerGood, ""
+		}),
+		grade("Content-Security-Policy", func(v string) (string, string) {
+			if v == "" {
+				if header.Get("Content-Security-Policy-Report-Only") != "" {
+					return headerWeak, "policy is only reported, not enforced"
+				}
+				return headerMissing, ""
+			}
+			return gradeCSP(csp)
+		}),
+		grade("X-Frame-Options", func(v string) (string, string) {
+			switch {
+			case strings.EqualFold(v, "DENY") || strings.EqualFold(v, "SAMEORIGIN"):
+				return headerGood, ""
+			case csp["frame-ancestors"] != nil:
+				return headerGood, "covered by CSP frame-ancestors"
+			case v == "":
+				return headerMissing, ""
+			}
+			return headerWeak, "should be DENY or SAMEORIGIN"
+		}),
+		grade("X-Content-Type-Options", func(v string) (string, string) {
+			switch {
+			case strings.EqualFold(v, "nosniff"):
+				return headerGood, ""
+			case v == "":
+				return headerMissing, ""
+			}
+			return headerWeak, "should be nosniff"
+		}),
+		grade("Referrer-Policy", func(v string) (string, string) {
+			if v == "" {
+				return headerMissing, ""
+			}
+			// The last recognized policy in a list wins, unsafe ones leak full URLs
+			policies := strings.Split(strings.ToLower(v), ",")
+			switch strings.TrimSpace(policies[len(policies)-1]) {
+			case "unsafe-url", "no-referrer-when-downgrade":
+				return headerWeak, "sends the full URL to other origins"
+			}
+			return headerGood, ""
+		}),
+		grade("Permissions-Policy", func(v string) (string, string) {
+			if v == "" {
+				return headerMissing, ""
+			}
+			return headerGood, ""
+		}),
+	}
+}
+
+// parseCSP splits a policy into its directives and their source lists
+func parseCSP(policy string) map[string][]string {
+	directives := map[string][]string{}
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(strings.ToLower(directive))
+		if len(fields) == 0 {
+			continue
+		}
+		// Only the first occurrence of a directive counts
+		if _, seen := directives[fields[0]]; !seen {
+			directives[fields[0]] = append([]string{}, fields[1:]...)
+		}
+	}
+	return directives
+}
+
+// gradeCSP flags policies that don't restrict scripts
+func gradeCSP(csp map[string][]string) (string, string) {
+	sources, ok := csp["script-src"]
+	if !ok {
+		sources, ok = csp["default-src"]
+	}
+	if !ok {
+		return headerWeak, "no script-src or default-src directive"
+	}
+
+	// unsafe-inline is ignored by browsers when a nonce or hash is present
+	hasNonce := false
+	for _, src := range sources {
+		hasNonce = hasNonce || strings.HasPrefix(src, "'nonce-") || strings.HasPrefix(src, "'sha")
+	}
+	for _, src := range sources {
+		switch {
+		case src == "'unsafe-inline'" && !hasNonce:
+			return headerWeak, "scripts allow 'unsafe-inline'"
+		case src == "'unsafe-eval'":
+			return headerWeak, "scripts allow 'unsafe-eval'"
+		case src == "*" || src == "http:" || src == "https:" || src == "data:":
+			return headerWeak, fmt.Sprintf("scripts allow any %s source", src)
+		}
+	}
+	return headerGood, ""
+}
+
+// securityGrade turns the header grades into a letter. Plain HTTP pages always get an F.
+func securityGrade(headers []models.SecurityHeader, isHTTPS bool) string {
+	if !isHTTPS {
+		return "F"
+	}
+
+	score := 0
+	for _, h := range headers {
+		switch h.Grade {
+		case headerGood:
+			score += 2
+		case headerWeak:
+			score++
+		}
+	}
+
+	percent := score * 100 / (2 * len(headers))
+	switch {
+	case percent >= 90:
+		return "A"
+	case percent >= 75:
+		return "B"
+	case percent >= 60:
+		return "C"
+	case percent >= 40:
+		return "D"
+	}
+	return "F"
+}
+
+// applyTLSDetails records the negotiated TLS version, cipher and leaf
+// certificate, and flags old protocols and expiring certificates
+func applyTLSDetails(report *models.SecurityReport, state *tls.ConnectionState, pageURL string, now time.Time) []linkcheck.Issue {
+	var issues []linkcheck.Issue
+	add := func(severity, code, message string) {
+		issues = append(issues, linkcheck.Issue{Category: "security", Severity: severity, Code: code, Message: message, Target: pageURL})
+	}
+
+	report.TLSVersion = tls.VersionName(state.Version)
+	report.TLSCipher = tls.CipherSuiteName(state.CipherSuite)
+	if state.Version < tls.VersionTLS12 {
+		add(linkcheck.SeverityWarning, "tls_outdated", fmt.Sprintf("Server negotiated %s, TLS 1.2 or newer is expected", report.TLSVersion))
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return issues
+	}
+	cert := state.PeerCertificates[0]
+	notAfter := cert.NotAfter
+	report.CertSubject = cert.Subject.String()
+	report.CertIssuer = cert.Issuer.String()
+	report.CertNotAfter = &notAfter
+	report.CertSANs = append(report.CertSANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		report.CertSANs = append(report.CertSANs, ip.String())
+	}
+
+	daysLeft := int(notAfter.Sub(now).Hours() / 24)
+	switch {
+	case now.After(notAfter):
+		add(linkcheck.SeverityError, "cert_expired", fmt.Sprintf("Certificate expired on %s", notAfter.Format("2006-01-02")))
+	case daysLeft < CertExpiryWarningDays:
+		add(linkcheck.SeverityWarning, "cert_expiring", fmt.Sprintf("Certificate expires in %d days on %s", daysLeft, notAfter.Format("2006-01-02")))
+	}
+
+	return issues
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// saveSecurityReport replaces the security report stored for a URL
+func saveSecurityReport(db *gorm.DB, urlEntry *models.URL) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.SecurityReport{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old security report: %w", err)
+	}
+	if urlEntry.Security == nil {
+		return nil
+	}
+
+	urlEntry.Security.URLID = urlEntry.ID
+	if err := db.Create(urlEntry.Security).Error; err != nil {
+		return fmt.Errorf("failed to create security report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+)
+
+func TestGradeCSP(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{"default-src 'self'", headerGood},
+		{"script-src 'self' 'nonce-abc' 'unsafe-inline'", headerGood},
+		{"script-src 'self' 'sha256-abc=' 'unsafe-inline'", headerGood},
+		{"img-src *", headerWeak},
+		{"script-src 'self' 'unsafe-inline'", headerWeak},
+		{"default-src 'self' 'unsafe-eval'", headerWeak},
+		{"script-src https:", headerWeak},
+		{"script-src *; default-src 'self'", headerWeak},
+		{"script-src 'self'; script-src *", headerGood},
+		{"DEFAULT-SRC 'self' 'UNSAFE-INLINE'", headerWeak},
+	}
+
+	for _, tt := range tests {
+		if got, note := gradeCSP(parseCSP(tt.policy)); got != tt.want {
+			t.Errorf("gradeCSP(%q) = %s (%s), want %s", tt.policy, got, note, tt.want)
+		}
+	}
+}
+
+func TestGradeSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		isHTTPS bool
+		graded  string // header whose grade is checked, defaults to header
+		want    string
+	}{
+		{"HSTS long max-age", "Strict-Transport-Security", "max-age=31536000; includeSubDomains", true, "", headerGood},
+		{"HSTS quoted max-age", "Strict-Transport-Security", `MAX-AGE="15552000"`, true, "", headerGood},
+		{"HSTS short max-age", "Strict-Transport-Security", "max-age=3600", true, "", headerWeak},
+		{"HSTS invalid max-age", "Strict-Transport-Security", "max-age=forever", true, "", headerWeak},
+		{"HSTS without max-age", "Strict-Transport-Security", "includeSubDomains", true, "", headerWeak},
+		{"HSTS missing", "Strict-Transport-Security", "", true, "", headerMissing},
+		{"HSTS on plain HTTP", "Strict-Transport-Security", "max-age=31536000", false, "", headerMissing},
+		{"CSP report only", "Content-Security-Policy-Report-Only", "default-src 'self'", true, "Content-Security-Policy", headerWeak},
+		{"frame options deny", "X-Frame-Options", "deny", true, "", headerGood},
+		{"frame options allow-from", "X-Frame-Options", "ALLOW-FROM https://example.com", true, "", headerWeak},
+		{"frame-ancestors instead of frame options", "Content-Security-Policy", "frame-ancestors 'none'", true, "X-Frame-Options", headerGood},
+		{"nosniff", "X-Content-Type-Options", "nosniff", true, "", headerGood},
+		{"referrer policy fallback list", "Referrer-Policy", "no-referrer, strict-origin-when-cross-origin", true, "", headerGood},
+		{"unsafe referrer policy", "Referrer-Policy", "unsafe-url", true, "", headerWeak},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set(tt.header, tt.value)
+
+			graded := tt.graded
+			if graded == "" {
+				graded = tt.header
+			}
+
+			for _, h := range gradeSecurityHeaders(header, tt.isHTTPS) {
+				if h.Name == graded {
+					if h.Grade != tt.want {
+						t.Errorf("%s graded %s (%s), want %s", h.Name, h.Grade, h.Note, tt.want)
+					}
+					return
+				}
+			}
+			t.Fatalf("%s wasn't graded", graded)
+		})
+	}
+}
+
+func TestSecurityGrade(t *testing.T) {
+	grades := func(gs ...string) []models.SecurityHeader {
+		headers := make([]models.SecurityHeader, len(gs))
+		for i, g := range gs {
+			headers[i] = models.SecurityHeader{Grade: g}
+		}
+		return headers
+	}
+
+	tests := []struct {
+		name    string
+		headers []models.SecurityHeader
+		isHTTPS bool
+		want    string
+	}{
+		{"all good", grades(headerGood, headerGood, headerGood, headerGood, headerGood, headerGood), true, "A"},
+		{"one weak", grades(headerGood, headerGood, headerGood, headerGood, headerGood, headerWeak), true, "A"},
+		{"one missing", grades(headerGood, headerGood, headerGood, headerGood, headerGood, headerMissing), true, "B"},
+		{"two missing", grades(headerGood, headerGood, headerGood, headerGood, headerMissing, headerMissing), true, "C"},
+		{"half missing", grades(headerGood, headerGood, headerGood, headerMissing, headerMissing, headerMissing), true, "D"},
+		{"all missing", grades(headerMissing, headerMissing, headerMissing, headerMissing, headerMissing, headerMissing), true, "F"},
+		{"plain HTTP", grades(headerGood, headerGood, headerGood, headerGood, headerGood, headerGood), false, "F"},
+	}
+
+	for _, tt := range tests {
+		if got := securityGrade(tt.headers, tt.isHTTPS); got != tt.want {
+			t.Errorf("%s: securityGrade() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeSecurityCookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "session=1; Secure; HttpOnly; SameSite=Lax")
+	header.Add("Set-Cookie", "tracking=2; SameSite=None")
+	resp := &http.Response{
+		Header:  header,
+		Request: &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}},
+	}
+
+	report, issues := analyzeSecurity(resp, "https://example.com/")
+
+	wantCookies := []models.CookieFlags{
+		{Name: "session", Secure: true, HttpOnly: true, SameSite: "Lax"},
+		{Name: "tracking", SameSite: "None"},
+	}
+	if !reflect.DeepEqual(report.Cookies, wantCookies) {
+		t.Errorf("cookies = %+v, want %+v", report.Cookies, wantCookies)
+	}
+
+	var cookieIssues []string
+	for _, issue := range issues {
+		switch issue.Code {
+		case "cookie_not_secure", "cookie_not_httponly", "cookie_samesite_missing", "cookie_samesite_none_insecure":
+			cookieIssues = append(cookieIssues, issue.Code)
+		}
+	}
+	want := []string{"cookie_not_secure", "cookie_not_httponly", "cookie_samesite_none_insecure"}
+	if !reflect.DeepEqual(cookieIssues, want) {
+		t.Errorf("cookie issues = %v, want %v", cookieIssues, want)
+	}
+}
+
+func TestApplyTLSDetails(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		version  uint16
+		notAfter time.Time
+		want     []string
+	}{
+		{"valid", tls.VersionTLS13, now.AddDate(1, 0, 0), nil},
+		{"expiring", tls.VersionTLS12, now.AddDate(0, 0, 10), []string{"cert_expiring"}},
+		{"expired", tls.VersionTLS12, now.AddDate(0, 0, -1), []string{"cert_expired"}},
+		{"old protocol", tls.VersionTLS10, now.AddDate(1, 0, 0), []string{"tls_outdated"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &tls.ConnectionState{
+				Version:          tt.version,
+				PeerCertificates: []*x509.Certificate{{NotAfter: tt.notAfter, DNSNames: []string{"example.com"}}},
+			}
+			report := &models.SecurityReport{}
+
+			var got []string
+			for _, issue := range applyTLSDetails(report, state, "https://example.com/", now) {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues = %v, want %v", got, tt.want)
+			}
+			if report.CertNotAfter == nil || !report.CertNotAfter.Equal(tt.notAfter) || !reflect.DeepEqual(report.CertSANs, []string{"example.com"}) {
+				t.Errorf("certificate details not recorded: %+v", report)
+			}
+		})
+	}
+}
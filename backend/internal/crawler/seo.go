@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func seoToModel(seo linkcheck.SEOData) *models.SEOMetadata {
+	hreflang := make([]models.HreflangAlternate, len(seo.Hreflang))
+	for i, alt := range seo.Hreflang {
+		hreflang[i] = models.HreflangAlternate{Lang: alt.Lang, URL: alt.URL}
+	}
+
+	return &models.SEOMetadata{
+		MetaDescription: seo.MetaDescription,
+		MetaRobots:      seo.MetaRobots,
+		Canonical:       seo.Canonical,
+		Viewport:        seo.Viewport,
+		Lang:            seo.Lang,
+		Hreflang:        hreflang,
+		OpenGraph:       seo.OpenGraph,
+		TwitterCard:     seo.TwitterCard,
+	}
+}
+
+// duplicateTitleIssues flags a title that is already used by other crawled URLs
+func duplicateTitleIssues(db *gorm.DB, urlEntry *models.URL) []linkcheck.Issue {
+	if urlEntry.Title == "" {
+		return nil
+	}
+
+	var others []string
+	if err := db.Model(&models.URL{}).
+		Where("title = ? AND id <> ?", urlEntry.Title, urlEntry.ID).
+		Limit(5).Pluck("url", &others).Error; err != nil {
+		fmt.Println("Failed to look up duplicate titles:", err)
+		return nil
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	return []linkcheck.Issue{{
+		Category: "seo",
+		Severity: linkcheck.SeverityWarning,
+		Code:     "title_duplicate",
+		Message:  fmt.Sprintf("Title is also used by %v", others),
+		Target:   urlEntry.URL,
+	}}
+}
+
+// saveSEOMetadata replaces the SEO metadata stored for a URL
+func saveSEOMetadata(db *gorm.DB, urlEntry *models.URL) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.SEOMetadata{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old SEO metadata: %w", err)
+	}
+	if urlEntry.SEO == nil {
+		return nil
+	}
+
+	urlEntry.SEO.URLID = urlEntry.ID
+	if err := db.Create(urlEntry.SEO).Error; err != nil {
+		return fmt.Errorf("failed to create SEO metadata: %w", err)
+	}
+	return nil
+}
@@ -1,82 +1,169 @@
 package analyzer
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
-	"strings"
+	"net/url"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/credentials"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/har"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
 	"gorm.io/gorm"
 )
 
-func CrawlURL(db *gorm.DB, urlEntry *models.URL) error {
+// MailtoMXResolver enables MX lookups for mailto links when set
+var MailtoMXResolver linkcheck.MXResolver
+
+func CrawlURL(db *gorm.DB, f *fetcher.Fetcher, urlEntry *models.URL) (err error) {
 	fmt.Println("Starting crawl for URL ID:", urlEntry.ID, "URL:", urlEntry.URL)
 
-	resp, err := http.Get(urlEntry.URL)
+	run := startRun(db, urlEntry)
+	var metrics *fetcher.Metrics
+	defer func() { finishRun(db, run, metrics, urlEntry, err) }()
+
+	// Every request of the crawl goes into its HAR log, saved once the page body is closed
+	harRecorder := har.NewRecorder("page_1", f.MaxBodySize())
+	defer saveHAR(db, run, urlEntry, harRecorder)
+
+	ctx, err := withCredentials(context.Background(), urlEntry)
+	if err != nil {
+		fmt.Println("Error loading crawl credentials:", err)
+		markFailed(db, urlEntry, 0, err.Error())
+		return err
+	}
+
+	ctx = fetcher.WithExchangeRecorder(ctx, harRecorder)
+
+	// Links share the credentials context, the page request also records its
+	// redirects and timings
+	linkCtx := ctx
+	if ArchiveLinkChecks {
+		linkCtx = withArchive(linkCtx, db, run, "link")
+	}
+	ctx = withArchive(ctx, db, run, "page")
+	ctx, metrics = fetcher.WithMetrics(ctx)
+	ctx, pageRedirects := fetcher.WithRedirectChain(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlEntry.URL, nil)
+	if err != nil {
+		fmt.Println("Error building request:", err)
+		return err
+	}
+
+	resp, err := f.Do(req)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+
+	// Keep the page's redirect chain even if the fetch failed on it
+	var redirectResults []linkcheck.LinkCheckResult
+	if len(pageRedirects.Hops) > 0 {
+		redirectResults = append(redirectResults, linkcheck.LinkCheckResult{URL: urlEntry.URL, Type: "page", Redirects: *pageRedirects})
+	}
+	if saveErr := saveRedirectChains(db, urlEntry, redirectResults); saveErr != nil {
+		fmt.Println("Failed to save redirect chains:", saveErr)
+		return saveErr
+	}
+
 	if err != nil {
 		fmt.Println("Error fetching URL:", err)
+		markFailed(db, urlEntry, 0, err.Error())
 		return err
 	}
-	defer resp.Body.Close()
+
+	// Relative links resolve against the page we ended up on after redirects
+	pageURL := resp.Request.URL.String()
+	urlEntry.FinalURL = pageURL
+	run.FinalURL = pageURL
+	fmt.Println("Final URL after", len(pageRedirects.Hops), "redirects:", pageURL)
 
 	fmt.Println("HTTP response status code:", resp.StatusCode)
-	if resp.StatusCode != 200 {
-		urlEntry.Status = fmt.Sprintf("failed with status %d", resp.StatusCode)
-		db.Save(urlEntry)
+	urlEntry.HTTPStatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		markFailed(db, urlEntry, resp.StatusCode, resp.Status)
 		return errors.New("failed to fetch URL")
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Headers, cookies and TLS apply to any response, HTML or not
+	var issues []linkcheck.Issue
+	var securityIssues []linkcheck.Issue
+	urlEntry.Security, securityIssues = analyzeSecurity(resp, pageURL)
+	issues = append(issues, securityIssues...)
+	fmt.Println("Security grade:", urlEntry.Security.Grade, "TLS:", urlEntry.Security.TLSVersion)
+
+	body, err := f.ReadBody(resp)
 	if err != nil {
-		fmt.Println("Error parsing HTML document:", err)
+		fmt.Println("Error reading response body:", err)
+		markFailed(db, urlEntry, resp.StatusCode, err.Error())
 		return err
 	}
-	fmt.Println("Parsed HTML document successfully")
-
-	urlEntry.HTMLVersion = linkcheck.DetectHTMLVersion()
-	fmt.Println("Detected HTML version:", urlEntry.HTMLVersion)
 
-	urlEntry.Title = strings.TrimSpace(doc.Find("title").Text())
-	fmt.Println("Page title:", urlEntry.Title)
-
-	urlEntry.H1Count = doc.Find("h1").Length()
-	urlEntry.H2Count = doc.Find("h2").Length()
-	urlEntry.H3Count = doc.Find("h3").Length()
-	urlEntry.H4Count = doc.Find("h4").Length()
-	urlEntry.H5Count = doc.Find("h5").Length()
-	urlEntry.H6Count = doc.Find("h6").Length()
-	fmt.Printf("Header counts h1:%d h2:%d h3:%d h4:%d h5:%d h6:%d\n",
-		urlEntry.H1Count, urlEntry.H2Count, urlEntry.H3Count,
-		urlEntry.H4Count, urlEntry.H5Count, urlEntry.H6Count)
+	urlEntry.ContentType = resp.Header.Get("Content-Type")
+	if urlEntry.ContentType == "" {
+		urlEntry.ContentType = http.DetectContentType(body)
+	}
+	urlEntry.ContentLength = int64(len(body))
+	fmt.Println("Content type:", urlEntry.ContentType, "size:", urlEntry.ContentLength)
 
-	internalLinks, externalLinks := linkcheck.CountLinks(doc, urlEntry.URL)
-	urlEntry.InternalLinks = internalLinks
-	urlEntry.ExternalLinks = externalLinks
-	fmt.Println("Counted links - internal:", internalLinks, "external:", externalLinks)
+	// Only HTML gets analyzed, anything else just keeps its metadata
+	var allLinks []linkcheck.Resource
+	if isHTML(urlEntry.ContentType) {
+		saveSnapshot(db, run, body, urlEntry.ContentType)
 
-	urlEntry.LoginFormFound = linkcheck.HasLoginForm(doc)
-	fmt.Println("Login form found:", urlEntry.LoginFormFound)
+		var htmlIssues []linkcheck.Issue
+		allLinks, htmlIssues, err = analyzeHTML(db, urlEntry, run, body, pageURL)
+		if err != nil {
+			markFailed(db, urlEntry, resp.StatusCode, err.Error())
+			return err
+		}
+		issues = append(issues, htmlIssues...)
+	} else {
+		fmt.Println("Skipping analysis of non-HTML content")
+		resetDocumentFields(urlEntry)
+		urlEntry.Charset = ""
+	}
 
-	allLinks := linkcheck.ExtractAllLinks(doc, urlEntry.URL)
-	fmt.Println("Extracted total links for broken link check:", len(allLinks))
+	// Only http(s) links get HTTP requests, the rest are validated by scheme
+	httpLinks, otherLinks := linkcheck.SplitByScheme(allLinks)
+	fmt.Println("HTTP links:", len(httpLinks), "non-HTTP links:", len(otherLinks))
 
-	// Check broken links
-	linkCheckResults, err := linkcheck.CheckBrokenLinks(allLinks)
+	// Check broken and redirected links
+	linkCheckResults, err := linkcheck.CheckLinks(linkCtx, f, httpLinks)
 	if err != nil {
 		fmt.Println("Warning: error during broken links check:", err)
 	} else {
-		fmt.Println("Broken links check completed, results count:", len(linkCheckResults))
+		fmt.Println("Links check completed, results count:", len(linkCheckResults))
 	}
+	addResourceWeights(run, linkCheckResults)
+
+	schemeResults, schemeIssues := linkcheck.CheckNonHTTPLinks(context.Background(), otherLinks, MailtoMXResolver)
+	linkCheckResults = append(linkCheckResults, schemeResults...)
+	issues = append(issues, schemeIssues...)
+	fmt.Println("Non-HTTP link check completed, broken:", len(schemeResults), "issues:", len(schemeIssues))
+
+	issues = append(issues, linkcheck.AnalyzeRedirectChain(urlEntry.URL, *pageRedirects, false)...)
 
 	// Convert helper results to model broken links
 	var brokenLinks []models.BrokenLink
 	for _, res := range linkCheckResults {
+		if len(res.Redirects.Hops) > 0 {
+			redirectResults = append(redirectResults, res)
+			issues = append(issues, linkcheck.AnalyzeRedirectChain(res.URL, res.Redirects, true)...)
+		}
+		if !res.Broken {
+			continue
+		}
 		brokenLinks = append(brokenLinks, models.BrokenLink{
-			URLID:      urlEntry.ID,
-			Link:       res.URL,
+			URLID:        urlEntry.ID,
+			Link:         res.URL,
+			ResourceType: res.Type,
+			Status:       res.Status,
 		})
 	}
 	fmt.Println("Converted broken links count:", len(brokenLinks))
@@ -100,7 +187,43 @@ func CrawlURL(db *gorm.DB, urlEntry *models.URL) error {
 	urlEntry.BrokenLinks = len(brokenLinks)
 	urlEntry.BrokenLinksDetails = brokenLinks
 
+	if err := saveRedirectChains(db, urlEntry, redirectResults); err != nil {
+		fmt.Println("Failed to save redirect chains:", err)
+		return err
+	}
+
+	if err := saveSEOMetadata(db, urlEntry); err != nil {
+		fmt.Println("Failed to save SEO metadata:", err)
+		return err
+	}
+
+	if err := saveStructuredData(db, urlEntry); err != nil {
+		fmt.Println("Failed to save structured data:", err)
+		return err
+	}
+
+	if err := saveHeadings(db, urlEntry); err != nil {
+		fmt.Println("Failed to save headings:", err)
+		return err
+	}
+
+	if err := saveForms(db, urlEntry); err != nil {
+		fmt.Println("Failed to save forms:", err)
+		return err
+	}
+
+	if err := saveSecurityReport(db, urlEntry); err != nil {
+		fmt.Println("Failed to save security report:", err)
+		return err
+	}
+
+	if err := saveIssues(db, urlEntry, issues); err != nil {
+		fmt.Println("Failed to save issues:", err)
+		return err
+	}
+
 	urlEntry.Status = "done"
+	urlEntry.FailureReason = ""
 	fmt.Println("Setting status to done and saving urlEntry")
 
 	err = db.Save(urlEntry).Error
@@ -112,3 +235,235 @@ func CrawlURL(db *gorm.DB, urlEntry *models.URL) error {
 	fmt.Println("Crawl finished successfully for URL ID:", urlEntry.ID)
 	return nil
 }
+
+// withCredentials attaches the URL's decrypted credentials to ctx. They are only
+// sent to the URL's own scheme and host, never to third-party links or
+// redirect targets.
+func withCredentials(ctx context.Context, urlEntry *models.URL) (context.Context, error) {
+	if urlEntry.Credentials == "" {
+		return ctx, nil
+	}
+
+	creds, err := credentials.Decrypt(urlEntry.Credentials)
+	if err != nil {
+		return ctx, err
+	}
+	target, err := url.Parse(urlEntry.URL)
+	if err != nil {
+		return ctx, err
+	}
+
+	return fetcher.WithAuthenticator(ctx, target, creds, creds.HTTPCookies())
+}
+
+// analyzeHTML decodes and parses an HTML body and runs every document
+// analyzer on it, filling in urlEntry and run. It returns the links and
+// resources of the page and the issues found.
+func analyzeHTML(db *gorm.DB, urlEntry *models.URL, run *models.CrawlRun, body []byte, pageURL string) ([]linkcheck.Resource, []linkcheck.Issue, error) {
+	// goquery expects UTF-8, so transcode the body first
+	cs := detectCharset(urlEntry.ContentType, body)
+	urlEntry.Charset = cs.Detected
+	issues := cs.Issues(pageURL)
+	fmt.Println("Detected charset:", cs.Detected, "header:", cs.Header, "bom:", cs.BOM, "meta:", cs.Meta)
+
+	decoded, err := decodeToUTF8(body, cs)
+	if err != nil {
+		fmt.Println("Error decoding body:", err)
+		return nil, nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(decoded))
+	if err != nil {
+		fmt.Println("Error parsing HTML document:", err)
+		return nil, nil, err
+	}
+	fmt.Println("Parsed HTML document successfully")
+
+	links, docIssues := analyzeDocument(urlEntry, doc, pageURL)
+	issues = append(issues, docIssues...)
+	issues = append(issues, analyzeContent(urlEntry, run, doc, len(decoded), pageURL)...)
+	issues = append(issues, duplicateTitleIssues(db, urlEntry)...)
+	issues = append(issues, duplicateContentIssues(db, urlEntry)...)
+	return links, issues, nil
+}
+
+// analyzeDocument fills in the page fields of urlEntry from the parsed
+// document and returns every link and resource it references along with
+// the issues found on the page
+func analyzeDocument(urlEntry *models.URL, doc *goquery.Document, pageURL string) ([]linkcheck.Resource, []linkcheck.Issue) {
+	var issues []linkcheck.Issue
+
+	urlEntry.HTMLVersion = linkcheck.DetectHTMLVersion()
+	fmt.Println("Detected HTML version:", urlEntry.HTMLVersion)
+
+	seo := linkcheck.ExtractSEO(doc, pageURL)
+	urlEntry.Title = seo.Title
+	urlEntry.SEO = seoToModel(seo)
+	issues = append(issues, seo.Issues(pageURL)...)
+	fmt.Println("Page title:", urlEntry.Title, "canonical:", seo.Canonical, "robots:", seo.MetaRobots)
+
+	urlEntry.H1Count = doc.Find("h1").Length()
+	urlEntry.H2Count = doc.Find("h2").Length()
+	urlEntry.H3Count = doc.Find("h3").Length()
+	urlEntry.H4Count = doc.Find("h4").Length()
+	urlEntry.H5Count = doc.Find("h5").Length()
+	urlEntry.H6Count = doc.Find("h6").Length()
+	fmt.Printf("Header counts h1:%d h2:%d h3:%d h4:%d h5:%d h6:%d\n",
+		urlEntry.H1Count, urlEntry.H2Count, urlEntry.H3Count,
+		urlEntry.H4Count, urlEntry.H5Count, urlEntry.H6Count)
+
+	headings := linkcheck.ExtractHeadings(doc)
+	urlEntry.Headings = headingsToModels(headings)
+	issues = append(issues, linkcheck.AnalyzeHeadings(headings, pageURL)...)
+	fmt.Println("Heading outline entries:", len(headings))
+
+	internalLinks, externalLinks := linkcheck.CountLinks(doc, pageURL)
+	urlEntry.InternalLinks = internalLinks
+	urlEntry.ExternalLinks = externalLinks
+	fmt.Println("Counted links - internal:", internalLinks, "external:", externalLinks)
+
+	entities, structuredIssues := linkcheck.ExtractStructuredData(doc, pageURL)
+	urlEntry.StructuredData = structuredDataToModels(entities)
+	issues = append(issues, structuredIssues...)
+	issues = append(issues, linkcheck.ValidateStructuredData(entities, pageURL)...)
+	fmt.Println("Structured data entities:", len(entities))
+
+	urlEntry.LoginFormFound = linkcheck.HasLoginForm(doc)
+	fmt.Println("Login form found:", urlEntry.LoginFormFound)
+
+	forms, formIssues := linkcheck.AnalyzeForms(doc, pageURL)
+	urlEntry.Forms = formsToModels(forms)
+	issues = append(issues, formIssues...)
+	fmt.Println("Forms found:", len(forms))
+
+	allLinks := linkcheck.ExtractAllLinks(doc, pageURL)
+	fmt.Println("Extracted total links and resources for broken link check:", len(allLinks))
+
+	mixedContent := linkcheck.FindMixedContent(allLinks, forms, pageURL)
+	urlEntry.MixedContentCount = len(mixedContent)
+	urlEntry.MixedContentActive, urlEntry.MixedContentPassive = linkcheck.CountMixedContent(mixedContent)
+	issues = append(issues, mixedContent...)
+	fmt.Println("Mixed content found:", urlEntry.MixedContentCount,
+		"active:", urlEntry.MixedContentActive, "passive:", urlEntry.MixedContentPassive)
+
+	accessibilityIssues := linkcheck.AuditAccessibility(doc)
+	for i := range accessibilityIssues {
+		accessibilityIssues[i].Target = pageURL
+	}
+	issues = append(issues, accessibilityIssues...)
+	fmt.Println("Accessibility findings:", len(accessibilityIssues))
+
+	return allLinks, issues
+}
+
+// resetDocumentFields clears results of a previous HTML crawl when the URL
+// now serves something else
+func resetDocumentFields(urlEntry *models.URL) {
+	urlEntry.HTMLVersion = ""
+	urlEntry.Title = ""
+	urlEntry.H1Count, urlEntry.H2Count, urlEntry.H3Count = 0, 0, 0
+	urlEntry.H4Count, urlEntry.H5Count, urlEntry.H6Count = 0, 0, 0
+	urlEntry.InternalLinks = 0
+	urlEntry.ExternalLinks = 0
+	urlEntry.LoginFormFound = false
+	urlEntry.MixedContentCount = 0
+	urlEntry.MixedContentActive, urlEntry.MixedContentPassive = 0, 0
+	urlEntry.WordCount = 0
+	urlEntry.TextRatio = 0
+	urlEntry.ReadingEase, urlEntry.ReadingGrade = 0, 0
+	urlEntry.DetectedLanguage = ""
+	urlEntry.ContentHash, urlEntry.SimHash = "", 0
+	urlEntry.SEO = nil
+	urlEntry.StructuredData = nil
+	urlEntry.Headings = nil
+	urlEntry.Forms = nil
+}
+
+// isHTML reports whether a Content-Type header describes an HTML or XHTML document
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// markFailed stores a failed crawl. statusCode is 0 when no response was received.
+func markFailed(db *gorm.DB, urlEntry *models.URL, statusCode int, reason string) {
+	urlEntry.Status = "failed"
+	urlEntry.HTTPStatusCode = statusCode
+	urlEntry.FailureReason = reason
+	if err := db.Save(urlEntry).Error; err != nil {
+		fmt.Println("Failed to save failed URL entry:", err)
+	}
+}
+
+// saveIssues replaces the issues stored for a URL with the ones found in this crawl
+func saveIssues(db *gorm.DB, urlEntry *models.URL, found []linkcheck.Issue) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.Issue{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old issues: %w", err)
+	}
+
+	issues := issuesToModels(urlEntry.ID, found)
+	if len(issues) > 0 {
+		if err := db.Create(&issues).Error; err != nil {
+			return fmt.Errorf("failed to create issues: %w", err)
+		}
+	}
+
+	urlEntry.Issues = issues
+	return nil
+}
+
+func issuesToModels(urlID uint, found []linkcheck.Issue) []models.Issue {
+	issues := make([]models.Issue, len(found))
+	for i, f := range found {
+		issues[i] = models.Issue{
+			URLID:    urlID,
+			Category: f.Category,
+			Severity: f.Severity,
+			Code:     f.Code,
+			Message:  f.Message,
+			Target:   f.Target,
+			Selector: f.Selector,
+		}
+	}
+	return issues
+}
+
+// saveRedirectChains replaces the redirect chains stored for a URL
+func saveRedirectChains(db *gorm.DB, urlEntry *models.URL, results []linkcheck.LinkCheckResult) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.RedirectChain{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old redirect chains: %w", err)
+	}
+
+	chains := make([]models.RedirectChain, len(results))
+	for i, res := range results {
+		hops := make([]models.RedirectHop, len(res.Redirects.Hops))
+		for j, hop := range res.Redirects.Hops {
+			hops[j] = models.RedirectHop{
+				Position:   j + 1,
+				URL:        hop.URL,
+				StatusCode: hop.StatusCode,
+				Location:   hop.Location,
+			}
+		}
+		chains[i] = models.RedirectChain{
+			URLID:        urlEntry.ID,
+			Source:       res.URL,
+			ResourceType: res.Type,
+			FinalURL:     res.Redirects.FinalURL,
+			Loop:         res.Redirects.Loop,
+			Hops:         hops,
+		}
+	}
+
+	if len(chains) > 0 {
+		if err := db.Create(&chains).Error; err != nil {
+			return fmt.Errorf("failed to create redirect chains: %w", err)
+		}
+	}
+
+	urlEntry.RedirectChains = chains
+	return nil
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/blobstore"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Snapshots keeps the raw HTML body of each crawl run when set
+var Snapshots blobstore.Store
+
+// SnapshotRetention decides how long snapshots are kept. A snapshot is
+// dropped once either limit is reached; zero disables a limit.
+type SnapshotRetention struct {
+	// KeepRuns is how many of the latest snapshots are kept per URL
+	KeepRuns int
+	// MaxAge drops snapshots of runs started longer ago
+	MaxAge time.Duration
+}
+
+var SnapshotPolicy = SnapshotRetention{KeepRuns: 5}
+
+// snapshotRefs makes storing a blob and recording the run that refers to it
+// atomic with checking a blob for references and deleting it, within this
+// process. Otherwise a blob found unreferenced could be deleted right after
+// another run stored the same body, leaving that run pointing at a missing
+// snapshot.
+var snapshotRefs sync.Mutex
+
+// saveSnapshot stores the body of the page for the run. Identical bodies,
+// like those of unchanged pages, share a single blob. The run is updated
+// right away so the blob is never left without a reference.
+func saveSnapshot(db *gorm.DB, run *models.CrawlRun, body []byte, contentType string) {
+	// Without a stored run the reference can't be recorded yet
+	if Snapshots == nil || run.ID == 0 {
+		return
+	}
+
+	snapshotRefs.Lock()
+	defer snapshotRefs.Unlock()
+
+	key, err := Snapshots.Put(context.Background(), body)
+	if err != nil {
+		fmt.Println("Failed to store HTML snapshot:", err)
+		return
+	}
+	if err := db.Model(run).Updates(map[string]any{"snapshot_key": key, "snapshot_content_type": contentType}).Error; err != nil {
+		fmt.Println("Failed to record HTML snapshot:", err)
+		return
+	}
+	run.SnapshotKey = key
+	run.SnapshotContentType = contentType
+}
+
+// pruneSnapshots drops the snapshots of a URL beyond the latest KeepRuns
+func pruneSnapshots(db *gorm.DB, urlID uint) {
+	if Snapshots == nil || SnapshotPolicy.KeepRuns <= 0 {
+		return
+	}
+
+	var runs []models.CrawlRun
+	if err := db.Select("id", "snapshot_key").Where("url_id = ? AND snapshot_key <> ''", urlID).
+		Order("started_at DESC").Order("id DESC").Find(&runs).Error; err != nil {
+		fmt.Println("Failed to load snapshots to prune:", err)
+		return
+	}
+	if len(runs) <= SnapshotPolicy.KeepRuns {
+		return
+	}
+	if err := releaseSnapshots(db, runs[SnapshotPolicy.KeepRuns:]); err != nil {
+		fmt.Println("Failed to prune snapshots:", err)
+	}
+}
+
+// PruneExpiredSnapshots drops the snapshots of runs older than the policy's MaxAge
+func PruneExpiredSnapshots(db *gorm.DB) error {
+	if Snapshots == nil || SnapshotPolicy.MaxAge <= 0 {
+		return nil
+	}
+	cutoff := time.Now().Add(-SnapshotPolicy.MaxAge)
+
+	var runs []models.CrawlRun
+	return db.Select("id", "snapshot_key").Where("snapshot_key <> '' AND started_at < ?", cutoff).
+		FindInBatches(&runs, 500, func(tx *gorm.DB, batch int) error {
+			return releaseSnapshots(db, runs)
+		}).Error
+}
+
+// releaseSnapshots unlinks the snapshots of the runs and deletes the blobs no
+// other run refers to anymore
+func releaseSnapshots(db *gorm.DB, runs []models.CrawlRun) error {
+	ids := make([]uint, len(runs))
+	keys := make(map[string]bool)
+	for i, run := range runs {
+		ids[i] = run.ID
+		keys[run.SnapshotKey] = true
+	}
+	if err := db.Model(&models.CrawlRun{}).Where("id IN ?", ids).Update("snapshot_key", "").Error; err != nil {
+		return err
+	}
+
+	for key := range keys {
+		if err := deleteUnreferencedSnapshot(db, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUnreferencedSnapshot deletes the blob under key unless a run refers to it
+func deleteUnreferencedSnapshot(db *gorm.DB, key string) error {
+	snapshotRefs.Lock()
+	defer snapshotRefs.Unlock()
+
+	var refs int64
+	if err := db.Model(&models.CrawlRun{}).Where("snapshot_key = ?", key).Count(&refs).Error; err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+	return Snapshots.Delete(context.Background(), key)
+}
+
+// ReanalyzeSnapshot runs the document analyzers again on the stored HTML of a
+// run, without fetching anything. Nothing is saved and links aren't checked,
+// so the returned URL only has the fields the document analyzers fill in
+// updated. urlEntry is left untouched.
+func ReanalyzeSnapshot(db *gorm.DB, urlEntry models.URL, run models.CrawlRun, body []byte) (*models.URL, []models.Issue, error) {
+	pageURL := run.FinalURL
+	if pageURL == "" {
+		pageURL = urlEntry.URL
+	}
+	urlEntry.FinalURL = pageURL
+	urlEntry.ContentType = run.SnapshotContentType
+	urlEntry.ContentLength = int64(len(body))
+
+	_, found, err := analyzeHTML(db, &urlEntry, &run, body, pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &urlEntry, issuesToModels(urlEntry.ID, found), nil
+}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func structuredDataToModels(entities []linkcheck.StructuredEntity) []models.StructuredData {
+	data := make([]models.StructuredData, len(entities))
+	for i, e := range entities {
+		data[i] = models.StructuredData{
+			Format:     e.Format,
+			Type:       e.Type,
+			Properties: e.Properties,
+		}
+	}
+	return data
+}
+
+// saveStructuredData replaces the structured data entities stored for a URL
+func saveStructuredData(db *gorm.DB, urlEntry *models.URL) error {
+	if err := db.Where("url_id = ?", urlEntry.ID).Delete(&models.StructuredData{}).Error; err != nil {
+		return fmt.Errorf("failed to delete old structured data: %w", err)
+	}
+	if len(urlEntry.StructuredData) == 0 {
+		return nil
+	}
+
+	for i := range urlEntry.StructuredData {
+		urlEntry.StructuredData[i].URLID = urlEntry.ID
+	}
+	if err := db.Create(&urlEntry.StructuredData).Error; err != nil {
+		return fmt.Errorf("failed to create structured data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrNoKey is returned when credentials are used before SetKey was called
+var ErrNoKey = errors.New("credentials encryption key is not configured")
+
+var encryptionKey []byte
+
+// Credentials are attached to a URL and sent with every request to its host
+type Credentials struct {
+	BasicUsername string            `json:"basic_username,omitempty"`
+	BasicPassword string            `json:"basic_password,omitempty"`
+	BearerToken   string            `json:"bearer_token,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Cookies       []Cookie          `json:"cookies,omitempty"`
+}
+
+// Cookie seeds the crawl's cookie jar with an existing session
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SetKey configures the AES key used to encrypt credentials at rest. The key
+// is base64 encoded and must decode to 16, 24 or 32 bytes.
+func SetKey(encoded string) error {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid credentials key: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("invalid credentials key length %d, want 16, 24 or 32 bytes", len(key))
+	}
+	encryptionKey = key
+	return nil
+}
+
+// IsEmpty reports whether no credential is set at all
+func (c *Credentials) IsEmpty() bool {
+	return c.BasicUsername == "" && c.BasicPassword == "" && c.BearerToken == "" &&
+		len(c.Headers) == 0 && len(c.Cookies) == 0
+}
+
+// Apply adds the credentials to a request. The fetcher only calls it for
+// requests to the URL's own host. Cookies aren't added here, they seed the
+// crawl's cookie jar instead.
+func (c *Credentials) Apply(req *http.Request) {
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	if c.BasicUsername != "" || c.BasicPassword != "" {
+		req.SetBasicAuth(c.BasicUsername, c.BasicPassword)
+	}
+	if c.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	}
+}
+
+// HTTPCookies returns the cookies to seed the crawl's cookie jar with
+func (c *Credentials) HTTPCookies() []*http.Cookie {
+	cookies := make([]*http.Cookie, len(c.Cookies))
+	for i, cookie := range c.Cookies {
+		cookies[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+	}
+	return cookies
+}
+
+// Encrypt serializes and seals the credentials with AES-GCM
+func Encrypt(c *Credentials) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens credentials sealed by Encrypt
+func Decrypt(encrypted string) (*Credentials, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted credentials: %w", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted credentials: too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	var c Credentials
+	if err := json.Unmarshal(plaintext, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	if encryptionKey == nil {
+		return nil, ErrNoKey
+	}
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
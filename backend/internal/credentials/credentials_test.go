@@ -0,0 +1,123 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func setTestKey(t *testing.T, key string) {
+	t.Helper()
+	prev := encryptionKey
+	t.Cleanup(func() { encryptionKey = prev })
+	if err := SetKey(base64.StdEncoding.EncodeToString([]byte(key))); err != nil {
+		t.Fatalf("SetKey: %v", err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	setTestKey(t, "0123456789abcdef0123456789abcdef")
+
+	creds := &Credentials{
+		BasicUsername: "admin",
+		BasicPassword: "s3cret",
+		BearerToken:   "token",
+		Headers:       map[string]string{"X-Api-Key": "key"},
+		Cookies:       []Cookie{{Name: "session", Value: "abc"}},
+	}
+	encrypted, err := Encrypt(creds)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if strings.Contains(encrypted, "s3cret") {
+		t.Fatal("encrypted credentials contain the plaintext password")
+	}
+
+	decrypted, err := Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !reflect.DeepEqual(decrypted, creds) {
+		t.Errorf("Decrypt() = %+v, want %+v", decrypted, creds)
+	}
+
+	again, err := Encrypt(creds)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if again == encrypted {
+		t.Error("encrypting twice gave the same ciphertext, want a fresh nonce each time")
+	}
+}
+
+func TestDecryptWithWrongKey(t *testing.T) {
+	setTestKey(t, "0123456789abcdef")
+	encrypted, err := Encrypt(&Credentials{BearerToken: "token"})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	setTestKey(t, "fedcba9876543210")
+	if _, err := Decrypt(encrypted); err == nil {
+		t.Error("Decrypt() with another key succeeded, want error")
+	}
+}
+
+func TestDecryptRejectsInvalidInput(t *testing.T) {
+	setTestKey(t, "0123456789abcdef")
+
+	tests := []struct {
+		name      string
+		encrypted string
+	}{
+		{"not base64", "%%%"},
+		{"shorter than the nonce", base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"tampered", base64.StdEncoding.EncodeToString([]byte("0123456789ab-not-a-sealed-box"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(tt.encrypted); err == nil {
+				t.Error("Decrypt() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestWithoutKey(t *testing.T) {
+	prev := encryptionKey
+	t.Cleanup(func() { encryptionKey = prev })
+	encryptionKey = nil
+
+	if _, err := Encrypt(&Credentials{}); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Encrypt() error = %v, want ErrNoKey", err)
+	}
+	if _, err := Decrypt("anything"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Decrypt() error = %v, want ErrNoKey", err)
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	prev := encryptionKey
+	t.Cleanup(func() { encryptionKey = prev })
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"AES-128", base64.StdEncoding.EncodeToString(make([]byte, 16)), false},
+		{"AES-192", base64.StdEncoding.EncodeToString(make([]byte, 24)), false},
+		{"AES-256", base64.StdEncoding.EncodeToString(make([]byte, 32)), false},
+		{"wrong length", base64.StdEncoding.EncodeToString(make([]byte, 20)), true},
+		{"not base64", "not base64!", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("SetKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
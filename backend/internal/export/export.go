@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	XLSX   Format = "xlsx"
+)
+
+// ParseFormat validates a format name, defaulting to CSV
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case "":
+		return CSV, nil
+	case CSV, NDJSON, XLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported export format %q, expected csv, ndjson or xlsx", name)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case NDJSON:
+		return "application/x-ndjson"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Writer streams rows to a file. Tabular formats write the values in the
+// order of the columns, NDJSON writes the record as a JSON object.
+type Writer interface {
+	Write(record any, values []any) error
+	// Flush sends buffered rows on to the underlying writer
+	Flush() error
+	// Close finishes the file; it doesn't close the underlying writer
+	Close() error
+}
+
+// NewWriter starts a file of the given format on w
+func NewWriter(w io.Writer, f Format, columns []string) (Writer, error) {
+	switch f {
+	case NDJSON:
+		bw := bufio.NewWriter(w)
+		return &ndjsonWriter{buf: bw, enc: json.NewEncoder(bw)}, nil
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: cw}, nil
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvWriter) Write(_ any, values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvValue(v)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(record any, _ []any) error {
+	return nw.enc.Encode(record)
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nw.buf.Flush()
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.buf.Flush()
+}
+
+// csvValue formats a value for CSV. Text starting like a formula is prefixed
+// with a quote so spreadsheets don't evaluate crawled titles and URLs.
+func csvValue(v any) string {
+	s := formatValue(v)
+	if _, isString := v.(string); isString && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", CSV, false},
+		{"csv", CSV, false},
+		{"NDJSON", NDJSON, false},
+		{"xlsx", XLSX, false},
+		{"json", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestCSVValue(t *testing.T) {
+	when := time.Date(2024, 5, 1, 14, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"plain text", "Home page", "Home page"},
+		{"formula", "=HYPERLINK(\"http://evil.example\")", "'=HYPERLINK(\"http://evil.example\")"},
+		{"plus", "+1 555 0100", "'+1 555 0100"},
+		{"minus", "-2", "'-2"},
+		{"at", "@SUM(A1)", "'@SUM(A1)"},
+		{"tab", "\tcmd", "'\tcmd"},
+		{"carriage return", "\rcmd", "'\rcmd"},
+		{"formula character later", "a=b", "a=b"},
+		{"empty", "", ""},
+		{"negative number", -2, "-2"},
+		{"float", 0.25, "0.25"},
+		{"bool", true, "true"},
+		{"time in UTC", when, "2024-05-01T12:00:00Z"},
+		{"nil time", (*time.Time)(nil), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvValue(tt.value); got != tt.want {
+				t.Errorf("csvValue(%#v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriters(t *testing.T) {
+	type row struct {
+		ID    uint   `json:"id"`
+		Title string `json:"title"`
+	}
+	rows := []row{{1, "Home, sweet home"}, {2, "=cmd"}}
+
+	tests := []struct {
+		format Format
+		check  func(t *testing.T, out []byte)
+	}{
+		{CSV, func(t *testing.T, out []byte) {
+			want := "id,title\n1,\"Home, sweet home\"\n2,'=cmd\n"
+			if string(out) != want {
+				t.Errorf("CSV = %q, want %q", out, want)
+			}
+		}},
+		{NDJSON, func(t *testing.T, out []byte) {
+			want := `{"id":1,"title":"Home, sweet home"}` + "\n" + `{"id":2,"title":"=cmd"}` + "\n"
+			if string(out) != want {
+				t.Errorf("NDJSON = %q, want %q", out, want)
+			}
+		}},
+		{XLSX, func(t *testing.T, out []byte) {
+			sheet := readZipPart(t, out, "xl/worksheets/sheet1.xml")
+			for _, want := range []string{
+				`<c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`,
+				`<c r="A2"><v>1</v></c>`,
+				`<c r="B2" t="inlineStr"><is><t xml:space="preserve">Home, sweet home</t></is></c>`,
+				`<c r="B3" t="inlineStr"><is><t xml:space="preserve">=cmd</t></is></c>`,
+				`</sheetData></worksheet>`,
+			} {
+				if !strings.Contains(sheet, want) {
+					t.Errorf("sheet is missing %s:\n%s", want, sheet)
+				}
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, tt.format, []string{"id", "title"})
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			for _, r := range rows {
+				if err := w.Write(r, []any{r.ID, r.Title}); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			tt.check(t, buf.Bytes())
+		})
+	}
+}
+
+func TestXLSXRowLimit(t *testing.T) {
+	w, err := newXLSXWriter(io.Discard, []string{"id"})
+	if err != nil {
+		t.Fatalf("newXLSXWriter: %v", err)
+	}
+	w.row = maxXLSXRows
+	if err := w.Write(nil, []any{1}); err != ErrTooManyRows {
+		t.Errorf("Write() past the last row = %v, want ErrTooManyRows", err)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func readZipPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip file: %v", err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("missing %s: %v", name, err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,81 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Authenticator adds credentials such as basic auth, tokens or headers to a request
+type Authenticator interface {
+	Apply(req *http.Request)
+}
+
+type authKey struct{}
+
+type hostAuthenticator struct {
+	scheme string
+	host   string
+	auth   Authenticator
+	jar    http.CookieJar
+}
+
+// WithAuthenticator returns a context whose requests to target's scheme and
+// host (host[:port]) are authenticated. Requests to any other host, or to the
+// same host over another scheme, are sent without the credentials.
+//
+// The authenticated requests share a cookie jar seeded with cookies, which
+// keeps the cookies the site sets for the rest of the crawl.
+func WithAuthenticator(ctx context.Context, target *url.URL, auth Authenticator, cookies []*http.Cookie) (context.Context, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return ctx, err
+	}
+	jar.SetCookies(target, cookies)
+
+	return context.WithValue(ctx, authKey{}, hostAuthenticator{
+		scheme: target.Scheme,
+		host:   target.Host,
+		auth:   auth,
+		jar:    jar,
+	}), nil
+}
+
+// matches reports whether u is the target the credentials belong to. A
+// downgrade from https to http on the same host doesn't match, so credentials
+// never go out in cleartext unless the target itself is plain http.
+func (a hostAuthenticator) matches(u *url.URL) bool {
+	return strings.EqualFold(u.Scheme, a.scheme) && strings.EqualFold(u.Host, a.host)
+}
+
+// authTransport applies credentials right before a request goes on the wire so
+// they're never part of the request the client copies headers from on redirect
+type authTransport struct {
+	base http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	a, ok := req.Context().Value(authKey{}).(hostAuthenticator)
+	if !ok || !a.matches(req.URL) {
+		return t.base.RoundTrip(req)
+	}
+
+	authReq := req.Clone(req.Context())
+	a.auth.Apply(authReq)
+	for _, cookie := range a.jar.Cookies(req.URL) {
+		authReq.AddCookie(cookie)
+	}
+
+	resp, err := t.base.RoundTrip(authReq)
+	if err != nil {
+		return nil, err
+	}
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		a.jar.SetCookies(req.URL, cookies)
+	}
+	return resp, nil
+}
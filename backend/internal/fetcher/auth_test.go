@@ -0,0 +1,141 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/credentials"
+)
+
+func TestCredentialsNotSentToOtherHosts(t *testing.T) {
+	var thirdParty http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		thirdParty = r.Header.Clone()
+	}))
+	defer other.Close()
+
+	var target http.Header
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.Header.Clone()
+		http.Redirect(w, r, other.URL+"/landing", http.StatusFound)
+	}))
+	defer site.Close()
+
+	f, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	creds := &credentials.Credentials{
+		BearerToken: "token",
+		Headers:     map[string]string{"X-Api-Key": "key"},
+		Cookies:     []credentials.Cookie{{Name: "session", Value: "abc"}},
+	}
+	siteURL, _ := url.Parse(site.URL)
+	ctx, err := WithAuthenticator(context.Background(), siteURL, creds, creds.HTTPCookies())
+	if err != nil {
+		t.Fatalf("WithAuthenticator: %v", err)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, site.URL, nil)
+	resp, err := f.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if target.Get("Authorization") != "Bearer token" || target.Get("X-Api-Key") != "key" || target.Get("Cookie") != "session=abc" {
+		t.Errorf("target host got headers %v, want the credentials", target)
+	}
+	if thirdParty == nil {
+		t.Fatal("redirect to the third-party host wasn't followed")
+	}
+	for _, name := range []string{"Authorization", "X-Api-Key", "Cookie"} {
+		if v := thirdParty.Get(name); v != "" {
+			t.Errorf("third-party host got %s: %q", name, v)
+		}
+	}
+}
+
+// fakeTransport answers every request with 200 and keeps a copy of it
+type fakeTransport struct {
+	requests []*http.Request
+	header   http.Header
+}
+
+func (rt *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Header: rt.header, Body: http.NoBody, Request: req}, nil
+}
+
+func TestAuthTransportScope(t *testing.T) {
+	target, _ := url.Parse("https://example.com/")
+	creds := &credentials.Credentials{
+		BasicUsername: "admin",
+		BasicPassword: "s3cret",
+		Cookies:       []credentials.Cookie{{Name: "session", Value: "abc"}},
+	}
+	ctx, err := WithAuthenticator(context.Background(), target, creds, creds.HTTPCookies())
+	if err != nil {
+		t.Fatalf("WithAuthenticator: %v", err)
+	}
+
+	tests := []struct {
+		url      string
+		wantAuth bool
+	}{
+		{"https://example.com/private", true},
+		{"https://EXAMPLE.com/other", true},
+		{"http://example.com/private", false},
+		{"https://example.com:8443/private", false},
+		{"https://www.example.com/private", false},
+		{"https://tracker.example.org/pixel", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			rt := &fakeTransport{}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			if _, err := (&authTransport{base: rt}).RoundTrip(req); err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+
+			sent := rt.requests[0]
+			_, _, hasAuth := sent.BasicAuth()
+			_, cookieErr := sent.Cookie("session")
+			if hasAuth != tt.wantAuth || (cookieErr == nil) != tt.wantAuth {
+				t.Errorf("sent Authorization %v and session cookie %v, want both %v", hasAuth, cookieErr == nil, tt.wantAuth)
+			}
+			if req.Header.Get("Authorization") != "" {
+				t.Error("credentials were added to the caller's request instead of a clone")
+			}
+		})
+	}
+}
+
+func TestAuthTransportKeepsSiteCookies(t *testing.T) {
+	target, _ := url.Parse("https://example.com/")
+	ctx, err := WithAuthenticator(context.Background(), target, &credentials.Credentials{}, nil)
+	if err != nil {
+		t.Fatalf("WithAuthenticator: %v", err)
+	}
+
+	rt := &fakeTransport{header: http.Header{"Set-Cookie": {"session=rotated; Path=/; Secure"}}}
+	transport := &authTransport{base: rt}
+	for _, link := range []string{"https://example.com/login", "https://example.com/account", "http://example.com/account"} {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip: %v", err)
+		}
+	}
+
+	if c, err := rt.requests[1].Cookie("session"); err != nil || c.Value != "rotated" {
+		t.Errorf("second request cookie = %v, %v, want the cookie set by the first response", c, err)
+	}
+	if _, err := rt.requests[2].Cookie("session"); err == nil {
+		t.Error("session cookie was sent over plain HTTP")
+	}
+}
@@ -0,0 +1,83 @@
+package fetcher
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config controls how pages and links are fetched
+type Config struct {
+	UserAgent string
+	// ProxyURL may be an http://, https:// or socks5:// URL. When empty the
+	// standard HTTP_PROXY/HTTPS_PROXY environment variables are used.
+	ProxyURL string
+	// ConnectTimeout bounds dialing and the TLS handshake
+	ConnectTimeout time.Duration
+	// ReadTimeout bounds the wait for response headers and any stall while reading the body
+	ReadTimeout time.Duration
+	// Timeout bounds the whole request including redirects and reading the body
+	Timeout     time.Duration
+	MaxBodySize int64
+	// InsecureSkipVerifyDomains lists domains (and their subdomains) whose certificates aren't verified
+	InsecureSkipVerifyDomains []string
+	// CABundleFile is a PEM file of extra trusted CAs
+	CABundleFile string
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		UserAgent:      "WebCrawler/1.0 (+https://github.com/UmutAkturk14/web-crawler)",
+		ConnectTimeout: 10 * time.Second,
+		ReadTimeout:    15 * time.Second,
+		Timeout:        30 * time.Second,
+		MaxBodySize:    10 << 20,
+	}
+}
+
+// ConfigFromEnv reads the CRAWLER_* environment variables on top of DefaultConfig
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if v := os.Getenv("CRAWLER_USER_AGENT"); v != "" {
+		config.UserAgent = v
+	}
+	config.ProxyURL = os.Getenv("CRAWLER_PROXY")
+	config.CABundleFile = os.Getenv("CRAWLER_CA_BUNDLE")
+
+	if v := os.Getenv("CRAWLER_INSECURE_DOMAINS"); v != "" {
+		for _, domain := range strings.Split(v, ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				config.InsecureSkipVerifyDomains = append(config.InsecureSkipVerifyDomains, domain)
+			}
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"CRAWLER_CONNECT_TIMEOUT": &config.ConnectTimeout,
+		"CRAWLER_READ_TIMEOUT":    &config.ReadTimeout,
+		"CRAWLER_TIMEOUT":         &config.Timeout,
+	}
+	for name, target := range durations {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return config, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*target = d
+		}
+	}
+
+	if v := os.Getenv("CRAWLER_MAX_BODY_SIZE"); v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return config, fmt.Errorf("invalid CRAWLER_MAX_BODY_SIZE: %w", err)
+		}
+		config.MaxBodySize = size
+	}
+
+	return config, nil
+}
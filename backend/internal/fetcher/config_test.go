@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CRAWLER_USER_AGENT", "TestBot/2.0")
+	t.Setenv("CRAWLER_PROXY", "socks5://127.0.0.1:1080")
+	t.Setenv("CRAWLER_INSECURE_DOMAINS", " example.com, ,internal.test ")
+	t.Setenv("CRAWLER_CONNECT_TIMEOUT", "2s")
+	t.Setenv("CRAWLER_READ_TIMEOUT", "500ms")
+	t.Setenv("CRAWLER_MAX_BODY_SIZE", "1024")
+	t.Setenv("CRAWLER_TIMEOUT", "")
+	t.Setenv("CRAWLER_CA_BUNDLE", "")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+
+	want := DefaultConfig()
+	want.UserAgent = "TestBot/2.0"
+	want.ProxyURL = "socks5://127.0.0.1:1080"
+	want.InsecureSkipVerifyDomains = []string{"example.com", "internal.test"}
+	want.ConnectTimeout = 2 * time.Second
+	want.ReadTimeout = 500 * time.Millisecond
+	want.MaxBodySize = 1024
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", config, want)
+	}
+}
+
+func TestConfigFromEnvRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"CRAWLER_CONNECT_TIMEOUT", "10"},
+		{"CRAWLER_READ_TIMEOUT", "soon"},
+		{"CRAWLER_TIMEOUT", "-"},
+		{"CRAWLER_MAX_BODY_SIZE", "10MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.name, tt.value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("ConfigFromEnv() with %s=%q succeeded, want error", tt.name, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Exchange is a single HTTP request and its response, including each hop of
+// a redirect chain. Request is the request before credentials are applied.
+type Exchange struct {
+	Request  *http.Request
+	Response *http.Response // nil when the request failed
+	Err      error
+
+	// Body is the response body as received, still content-encoded. It only
+	// holds what was read before the body was closed, up to MaxBodySize.
+	Body      []byte
+	Truncated bool
+
+	RemoteAddr string
+	Start      time.Time // request sent
+	Headers    time.Time // response headers received
+	End        time.Time // body closed
+	Timings    ExchangeTimings
+}
+
+// ExchangeTimings are the phases of an exchange as HAR defines them. Phases
+// that didn't happen, like DNS on a reused connection, are -1.
+type ExchangeTimings struct {
+	Blocked time.Duration // waiting for a connection
+	DNS     time.Duration
+	Connect time.Duration // including TLS
+	TLS     time.Duration
+	Send    time.Duration
+	Wait    time.Duration // until the first response byte
+	Receive time.Duration // until the body was closed
+}
+
+// ExchangeRecorder receives every exchange made with a context from WithExchangeRecorder.
+// Exchanges of one context may be recorded concurrently.
+type ExchangeRecorder interface {
+	RecordExchange(ex *Exchange)
+}
+
+type recordersKey struct{}
+
+// WithExchangeRecorder returns a context whose requests are passed to r once
+// their response body is closed, along with those of any recorder already set
+func WithExchangeRecorder(ctx context.Context, r ExchangeRecorder) context.Context {
+	prev, _ := ctx.Value(recordersKey{}).([]ExchangeRecorder)
+	recorders := append(append([]ExchangeRecorder(nil), prev...), r)
+	return context.WithValue(ctx, recordersKey{}, recorders)
+}
+
+// recordingTransport captures exchanges for the recorders of the request context.
+// It wraps authTransport so credentials never end up in a recording.
+type recordingTransport struct {
+	base    http.RoundTripper
+	maxBody int64
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorders, _ := req.Context().Value(recordersKey{}).([]ExchangeRecorder)
+	if len(recorders) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ex := &Exchange{Request: req, Start: time.Now()}
+	trace := &exchangeTrace{}
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace())))
+	ex.Headers = time.Now()
+	ex.RemoteAddr, ex.Timings = trace.result(ex.Start, ex.Headers)
+	if err != nil {
+		ex.Err = err
+		ex.End = ex.Headers
+		for _, r := range recorders {
+			r.RecordExchange(ex)
+		}
+		return nil, err
+	}
+
+	ex.Response = resp
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		ex:         ex,
+		recorders:  recorders,
+		limit:      t.maxBody,
+		complete:   req.Method == http.MethodHead || resp.ContentLength == 0,
+	}
+	return resp, nil
+}
+
+// recordingBody keeps a copy of what is read from a response body and hands
+// the exchange to the recorders when it is closed
+type recordingBody struct {
+	io.ReadCloser
+	ex        *Exchange
+	recorders []ExchangeRecorder
+	limit     int64
+	buf       []byte
+	complete  bool
+	once      sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if b.limit > 0 && int64(len(b.buf)+n) > b.limit {
+		b.buf = append(b.buf, p[:b.limit-int64(len(b.buf))]...)
+		b.ex.Truncated = true
+	} else {
+		b.buf = append(b.buf, p[:n]...)
+	}
+	if err == io.EOF {
+		b.complete = true
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.ex.Body = b.buf
+		b.ex.Truncated = b.ex.Truncated || !b.complete
+		b.ex.End = time.Now()
+		b.ex.Timings.Receive = b.ex.End.Sub(b.ex.Headers)
+		for _, r := range b.recorders {
+			r.RecordExchange(b.ex)
+		}
+	})
+	return err
+}
+
+// exchangeTrace collects the connection events of a single request
+type exchangeTrace struct {
+	mu           sync.Mutex
+	remoteAddr   string
+	reused       bool
+	gotConn      time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wrote        time.Time
+	firstByte    time.Time
+}
+
+func (t *exchangeTrace) clientTrace() *httptrace.ClientTrace {
+	now := func(ts *time.Time, first bool) {
+		t.mu.Lock()
+		if !first || ts.IsZero() {
+			*ts = time.Now()
+		}
+		t.mu.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { now(&t.dnsStart, true) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { now(&t.dnsDone, false) },
+		ConnectStart:         func(string, string) { now(&t.connectStart, true) },
+		ConnectDone:          func(string, string, error) { now(&t.connectDone, false) },
+		TLSHandshakeStart:    func() { now(&t.tlsStart, true) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { now(&t.tlsDone, false) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wrote, false) },
+		GotFirstResponseByte: func() { now(&t.firstByte, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.gotConn = time.Now()
+			t.reused = info.Reused
+			t.remoteAddr = info.Conn.RemoteAddr().String()
+			t.mu.Unlock()
+		},
+	}
+}
+
+// result turns the events into the phases of a request sent at start
+// whose response headers arrived at headers
+func (t *exchangeTrace) result(start, headers time.Time) (string, ExchangeTimings) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phase := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return -1
+		}
+		return to.Sub(from)
+	}
+	timings := ExchangeTimings{
+		DNS:     phase(t.dnsStart, t.dnsDone),
+		Connect: phase(t.connectStart, t.connectDone),
+		TLS:     phase(t.tlsStart, t.tlsDone),
+		Send:    phase(t.gotConn, t.wrote),
+		Wait:    phase(t.wrote, t.firstByte),
+		Receive: -1,
+	}
+	if timings.Connect >= 0 && timings.TLS >= 0 {
+		timings.Connect += timings.TLS
+	}
+	if t.firstByte.IsZero() && !t.wrote.IsZero() {
+		timings.Wait = headers.Sub(t.wrote)
+	}
+
+	// Whatever preceded the connection that wasn't resolving or connecting is time spent blocked
+	timings.Blocked = phase(start, t.gotConn)
+	if timings.Blocked > 0 && !t.reused {
+		timings.Blocked -= max(timings.DNS, 0) + max(timings.Connect, 0)
+		timings.Blocked = max(timings.Blocked, 0)
+	}
+	return t.remoteAddr, timings
+}
@@ -0,0 +1,254 @@
+package fetcher
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrBodyTooLarge is returned by ReadBody when a response exceeds MaxBodySize
+	ErrBodyTooLarge = errors.New("response body exceeds maximum size")
+	// ErrReadTimeout is returned when reading a response body stalls for longer than ReadTimeout
+	ErrReadTimeout = errors.New("response body read timed out")
+)
+
+// Fetcher is the HTTP client shared by page crawls and link checks
+type Fetcher struct {
+	client *http.Client
+	config Config
+	roots  *x509.CertPool
+}
+
+// New builds a Fetcher from the given configuration
+func New(config Config) (*Fetcher, error) {
+	f := &Fetcher{config: config}
+
+	if config.CABundleFile != "" {
+		pem, err := os.ReadFile(config.CABundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", config.CABundleFile)
+		}
+		f.roots = roots
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		// http.Transport handles http, https and socks5 proxy URLs
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{RootCAs: f.roots}
+	if len(config.InsecureSkipVerifyDomains) > 0 {
+		// Verification is done by hand so it can be skipped per domain
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = f.verifyConnection
+	}
+
+	dialer := &net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.ConnectTimeout,
+		ResponseHeaderTimeout: config.ReadTimeout,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	f.client = &http.Client{
+		Transport:     &recordingTransport{base: &authTransport{base: transport}, maxBody: config.MaxBodySize},
+		Timeout:       config.Timeout,
+		CheckRedirect: CheckRedirect,
+	}
+
+	return f, nil
+}
+
+// Do sends the request with the configured User-Agent. Compressed responses
+// are requested explicitly so ReadBody can measure their size on the wire.
+// A body read that stalls for longer than ReadTimeout cancels the request.
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" && f.config.UserAgent != "" {
+		req.Header.Set("User-Agent", f.config.UserAgent)
+	}
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+	if f.config.ReadTimeout <= 0 {
+		return f.client.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	resp, err := f.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &stallTimeoutBody{ReadCloser: resp.Body, timeout: f.config.ReadTimeout, cancel: cancel}
+	return resp, nil
+}
+
+// MaxBodySize is the largest body ReadBody accepts, 0 when there is no limit
+func (f *Fetcher) MaxBodySize() int64 {
+	return f.config.MaxBodySize
+}
+
+// ReadBody reads and decodes the whole response body, failing once it grows
+// past MaxBodySize. Sizes are recorded in the request's Metrics, if any.
+func (f *Fetcher) ReadBody(resp *http.Response) ([]byte, error) {
+	raw := &countingReader{r: resp.Body}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+	var reader io.Reader = raw
+	switch encoding {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(raw)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		if err == nil {
+			defer zr.Close()
+			reader = zr
+		}
+	case "deflate":
+		zr, err := zlib.NewReader(raw)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("invalid deflate body: %w", err)
+		}
+		if err == nil {
+			defer zr.Close()
+			reader = zr
+		}
+	}
+
+	// The limit applies to the decoded body so compressed bombs are caught too
+	var body []byte
+	var err error
+	if f.config.MaxBodySize <= 0 {
+		body, err = io.ReadAll(reader)
+	} else {
+		body, err = io.ReadAll(io.LimitReader(reader, f.config.MaxBodySize+1))
+		if err == nil && int64(len(body)) > f.config.MaxBodySize {
+			err = fmt.Errorf("%w (%d bytes)", ErrBodyTooLarge, f.config.MaxBodySize)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Request != nil {
+		if m := metricsFrom(resp.Request.Context()); m != nil {
+			m.recordBody(encoding, raw.n, int64(len(body)))
+		}
+	}
+	return body, nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// verifyConnection does the standard certificate verification unless the
+// server belongs to one of the domains configured to skip it
+func (f *Fetcher) verifyConnection(cs tls.ConnectionState) error {
+	if f.skipVerify(cs.ServerName) {
+		return nil
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls: server sent no certificates")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         f.roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// skipVerify matches a host against the insecure domains, including subdomains
+func (f *Fetcher) skipVerify(host string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range f.config.InsecureSkipVerifyDomains {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// stallTimeoutBody cancels its request when a single read waits for longer
+// than timeout. The timer only runs while a read is in progress, so neither
+// the caller's own processing nor connections idling in the pool are affected.
+type stallTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+func (b *stallTimeoutBody) Read(p []byte) (int, error) {
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.timeout, func() {
+			b.expired.Store(true)
+			b.cancel()
+		})
+	} else {
+		b.timer.Reset(b.timeout)
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Stop()
+	if err != nil && err != io.EOF && b.expired.Load() {
+		err = fmt.Errorf("%w after %s", ErrReadTimeout, b.timeout)
+	}
+	return n, err
+}
+
+func (b *stallTimeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
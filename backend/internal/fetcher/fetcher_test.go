@@ -0,0 +1,227 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetcherUsesProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	config := DefaultConfig()
+	config.ProxyURL = proxy.URL
+	f, err := New(config)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid/page", nil)
+	resp, err := f.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if proxied != "http://example.invalid/page" {
+		t.Errorf("proxy received %q, want the absolute target URL", proxied)
+	}
+}
+
+func TestNewRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"invalid proxy", Config{ProxyURL: "://proxy"}},
+		{"missing CA bundle", Config{CABundleFile: "testdata/does-not-exist.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("New() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestFetcherUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+	}))
+	defer server.Close()
+
+	f, err := New(Config{UserAgent: "TestBot/1.0"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "TestBot/1.0"},
+		{"Custom/3.0", "Custom/3.0"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if tt.header != "" {
+			req.Header.Set("User-Agent", tt.header)
+		}
+		resp, err := f.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		resp.Body.Close()
+		if got != tt.want {
+			t.Errorf("User-Agent = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestFetcherTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"read timeout", Config{ReadTimeout: 50 * time.Millisecond}},
+		{"total timeout", Config{Timeout: 50 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.config)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			resp, err := f.Do(req)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("Do() succeeded, want a timeout")
+			}
+		})
+	}
+}
+
+func TestFetcherBodyReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, " body")
+	}))
+	defer server.Close()
+
+	f, err := New(Config{ReadTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := f.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := f.ReadBody(resp); !errors.Is(err, ErrReadTimeout) {
+		t.Errorf("ReadBody() error = %v, want ErrReadTimeout", err)
+	}
+}
+
+// An idle keep-alive connection outlives ReadTimeout and is reused
+func TestFetcherReusesIdleConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	f, err := New(Config{ReadTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var reused []bool
+	for i := 0; i < 2; i++ {
+		trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = append(reused, info.Reused) }}
+		req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, server.URL, nil)
+		resp, err := f.Do(req)
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if _, err := f.ReadBody(resp); err != nil {
+			t.Fatalf("ReadBody: %v", err)
+		}
+		resp.Body.Close()
+		time.Sleep(150 * time.Millisecond)
+	}
+
+	if len(reused) != 2 || !reused[1] {
+		t.Errorf("connection reuse = %v, want the second request on the idle connection", reused)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int64
+		body    string
+		wantErr error
+	}{
+		{"unlimited", 0, "hello world", nil},
+		{"within limit", 11, "hello world", nil},
+		{"over limit", 5, "hello world", ErrBodyTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Fetcher{config: Config{MaxBodySize: tt.max}}
+			resp := &http.Response{Body: io.NopCloser(strings.NewReader(tt.body))}
+
+			body, err := f.ReadBody(resp)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadBody() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(body) != tt.body {
+				t.Errorf("ReadBody() = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestSkipVerify(t *testing.T) {
+	f := &Fetcher{config: Config{InsecureSkipVerifyDomains: []string{"Example.com"}}}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"staging.EXAMPLE.com", true},
+		{"notexample.com", false},
+		{"example.com.evil.test", false},
+	}
+	for _, tt := range tests {
+		if got := f.skipVerify(tt.host); got != tt.want {
+			t.Errorf("skipVerify(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
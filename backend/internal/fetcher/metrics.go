@@ -0,0 +1,113 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Metrics records the timings and sizes of a request made with a context from WithMetrics.
+// DNS, connect and TLS times belong to the last connection opened while
+// following redirects and are zero when only pooled connections were used.
+type Metrics struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	firstByte    time.Time
+	bodyDone     time.Time
+
+	// Set by ReadBody
+	ContentEncoding string
+	TransferSize    int64 // body bytes received, before decoding
+	BodySize        int64 // body bytes after decoding
+}
+
+// Phases are the durations of a request. TTFB and Total are measured from the
+// first request, so they include any redirects.
+type Phases struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Download time.Duration
+	Total    time.Duration
+}
+
+type metricsKey struct{}
+
+// WithMetrics returns a context that records timings of any request made with it
+func WithMetrics(ctx context.Context) (context.Context, *Metrics) {
+	m := &Metrics{}
+	now := func(t *time.Time) {
+		m.mu.Lock()
+		*t = time.Now()
+		m.mu.Unlock()
+	}
+
+	trace := &httptrace.ClientTrace{
+		GetConn: func(string) {
+			m.mu.Lock()
+			if m.start.IsZero() {
+				m.start = time.Now()
+			}
+			m.mu.Unlock()
+		},
+		DNSStart:             func(httptrace.DNSStartInfo) { now(&m.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { now(&m.dnsDone) },
+		ConnectStart:         func(string, string) { now(&m.connectStart) },
+		ConnectDone:          func(string, string, error) { now(&m.connectDone) },
+		TLSHandshakeStart:    func() { now(&m.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { now(&m.tlsDone) },
+		GotFirstResponseByte: func() { now(&m.firstByte) },
+	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+	return context.WithValue(ctx, metricsKey{}, m), m
+}
+
+func metricsFrom(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsKey{}).(*Metrics)
+	return m
+}
+
+// Phases returns the recorded durations. Phases that didn't happen are zero.
+func (m *Metrics) Phases() Phases {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	between := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() || to.Before(from) {
+			return 0
+		}
+		return to.Sub(from)
+	}
+
+	end := m.bodyDone
+	if end.IsZero() {
+		end = m.firstByte
+	}
+	return Phases{
+		DNS:      between(m.dnsStart, m.dnsDone),
+		Connect:  between(m.connectStart, m.connectDone),
+		TLS:      between(m.tlsStart, m.tlsDone),
+		TTFB:     between(m.start, m.firstByte),
+		Download: between(m.firstByte, m.bodyDone),
+		Total:    between(m.start, end),
+	}
+}
+
+// recordBody is called once the body has been read
+func (m *Metrics) recordBody(encoding string, transferSize, bodySize int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bodyDone = time.Now()
+	m.ContentEncoding = encoding
+	m.TransferSize = transferSize
+	m.BodySize = bodySize
+}
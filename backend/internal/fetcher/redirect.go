@@ -0,0 +1,66 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// MaxRedirects is how many hops are followed before giving up
+const MaxRedirects = 10
+
+var (
+	ErrRedirectLoop     = errors.New("redirect loop detected")
+	ErrTooManyRedirects = fmt.Errorf("stopped after %d redirects", MaxRedirects)
+)
+
+// RedirectHop is a single redirect response: the URL that was requested,
+// the status it answered with and where it pointed to
+type RedirectHop struct {
+	URL        string
+	StatusCode int
+	Location   string
+}
+
+// RedirectChain records every hop followed for one request
+type RedirectChain struct {
+	Hops     []RedirectHop
+	FinalURL string
+	Loop     bool
+}
+
+type redirectChainKey struct{}
+
+// WithRedirectChain returns a context that records the redirects of any request made with it
+func WithRedirectChain(ctx context.Context) (context.Context, *RedirectChain) {
+	chain := &RedirectChain{}
+	return context.WithValue(ctx, redirectChainKey{}, chain), chain
+}
+
+// CheckRedirect is used as http.Client.CheckRedirect. It records each hop in
+// the chain attached to the request context and stops on loops and long chains.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	chain, _ := req.Context().Value(redirectChainKey{}).(*RedirectChain)
+	if chain != nil && req.Response != nil {
+		chain.Hops = append(chain.Hops, RedirectHop{
+			URL:        req.Response.Request.URL.String(),
+			StatusCode: req.Response.StatusCode,
+			Location:   req.Response.Header.Get("Location"),
+		})
+		chain.FinalURL = req.URL.String()
+	}
+
+	for _, prev := range via {
+		if prev.URL.String() == req.URL.String() {
+			if chain != nil {
+				chain.Loop = true
+			}
+			return ErrRedirectLoop
+		}
+	}
+	if len(via) >= MaxRedirects {
+		return ErrTooManyRedirects
+	}
+	return nil
+}
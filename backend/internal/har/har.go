@@ -0,0 +1,301 @@
+package har
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+)
+
+// HAR is an HTTP Archive 1.2 document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Page struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	ID              string      `json:"id"`
+	Title           string      `json:"title"`
+	PageTimings     PageTimings `json:"pageTimings"`
+}
+
+// PageTimings are browser load events, which a crawler doesn't have
+type PageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+type Entry struct {
+	Pageref         string    `json:"pageref,omitempty"`
+	StartedDateTime time.Time `json:"startedDateTime"`
+	Time            float64   `json:"time"`
+	Request         Request   `json:"request"`
+	Response        Response  `json:"response"`
+	Cache           struct{}  `json:"cache"`
+	Timings         Timings   `json:"timings"`
+	ServerIPAddress string    `json:"serverIPAddress,omitempty"`
+	// Error is why no response was received, as a custom field
+	Error string `json:"_error,omitempty"`
+}
+
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []Cookie    `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+	// Truncated marks a body that wasn't read to the end, as a custom field
+	Truncated bool `json:"_truncated,omitempty"`
+}
+
+type Content struct {
+	Size        int64  `json:"size"`
+	Compression int64  `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+type Cookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings are in milliseconds, -1 for phases that didn't happen
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// redactedHeaders never have their values recorded, since HAR files get shared
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// Recorder collects the exchanges of a crawl as HAR entries. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	pageRef string
+	maxBody int64
+	entries []Entry
+}
+
+// NewRecorder returns a recorder whose entries belong to the page with the
+// given ID. Bodies are decoded up to maxBody bytes to measure them, 0 for no limit.
+func NewRecorder(pageRef string, maxBody int64) *Recorder {
+	return &Recorder{pageRef: pageRef, maxBody: maxBody}
+}
+
+func (r *Recorder) RecordExchange(ex *fetcher.Exchange) {
+	entry := newEntry(ex, r.maxBody)
+	entry.Pageref = r.pageRef
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+// HAR returns the recorded entries in the order they were started
+func (r *Recorder) HAR(creator Creator, page Page) HAR {
+	r.mu.Lock()
+	entries := append([]Entry{}, r.entries...)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+	page.ID = r.pageRef
+	page.PageTimings = PageTimings{OnContentLoad: -1, OnLoad: -1}
+	return HAR{Log: Log{Version: "1.2", Creator: creator, Pages: []Page{page}, Entries: entries}}
+}
+
+func newEntry(ex *fetcher.Exchange, maxBody int64) Entry {
+	req := ex.Request
+	entry := Entry{
+		StartedDateTime: ex.Start,
+		Request: Request{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: protoOrDefault(req.Proto),
+			Cookies:     cookieNames(req.Cookies()),
+			Headers:     headerList(req.Header),
+			QueryString: []NameValue{},
+			HeadersSize: -1,
+			BodySize:    0,
+		},
+		Timings: Timings{
+			Blocked: millis(ex.Timings.Blocked),
+			DNS:     millis(ex.Timings.DNS),
+			Connect: millis(ex.Timings.Connect),
+			Send:    millis(ex.Timings.Send),
+			Wait:    millis(ex.Timings.Wait),
+			Receive: millis(ex.Timings.Receive),
+			SSL:     millis(ex.Timings.TLS),
+		},
+	}
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.Slice(entry.Request.QueryString, func(i, j int) bool {
+		return entry.Request.QueryString[i].Name < entry.Request.QueryString[j].Name
+	})
+	if host, _, err := net.SplitHostPort(ex.RemoteAddr); err == nil {
+		entry.ServerIPAddress = host
+	}
+
+	// The send phase is required, everything else may be -1
+	entry.Timings.Send = max(entry.Timings.Send, 0)
+	for _, t := range []float64{entry.Timings.Blocked, entry.Timings.DNS, entry.Timings.Connect,
+		entry.Timings.Send, entry.Timings.Wait, entry.Timings.Receive} {
+		entry.Time += max(t, 0)
+	}
+	entry.Time = math.Round(entry.Time*1000) / 1000
+
+	resp := ex.Response
+	if resp == nil {
+		entry.Error = ex.Err.Error()
+		entry.Response = Response{
+			Cookies:     []Cookie{},
+			Headers:     []NameValue{},
+			Content:     Content{MimeType: "x-unknown"},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		return entry
+	}
+
+	size := decodedSize(resp.Header.Get("Content-Encoding"), ex.Body, maxBody)
+	entry.Response = Response{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		HTTPVersion: protoOrDefault(resp.Proto),
+		Cookies:     cookieNames(resp.Cookies()),
+		Headers:     headerList(resp.Header),
+		Content: Content{
+			Size:        size,
+			Compression: size - int64(len(ex.Body)),
+			MimeType:    resp.Header.Get("Content-Type"),
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(ex.Body)),
+		Truncated:   ex.Truncated,
+	}
+	return entry
+}
+
+func headerList(header http.Header) []NameValue {
+	list := []NameValue{}
+	for name, values := range header {
+		for _, v := range values {
+			if redactedHeaders[name] {
+				v = "[redacted]"
+			}
+			list = append(list, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// cookieNames lists cookies without their values, like headerList
+func cookieNames(cookies []*http.Cookie) []Cookie {
+	list := []Cookie{}
+	for _, c := range cookies {
+		list = append(list, Cookie{Name: c.Name, Value: "[redacted]"})
+	}
+	return list
+}
+
+// decodedSize is the size of a content-encoded body once decoded, counting
+// at most limit bytes so a compression bomb isn't inflated in full
+func decodedSize(encoding string, body []byte, limit int64) int64 {
+	var r io.Reader
+	var err error
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			r, err = flate.NewReader(bytes.NewReader(body)), nil
+		}
+	default:
+		return int64(len(body))
+	}
+	if err != nil {
+		return int64(len(body))
+	}
+	if limit > 0 {
+		r = io.LimitReader(r, limit)
+	}
+	// A truncated body decodes up to where it was cut
+	n, _ := io.Copy(io.Discard, r)
+	return n
+}
+
+// millis converts a phase to milliseconds, keeping -1 for phases that didn't happen
+func millis(d time.Duration) float64 {
+	if d < 0 {
+		return -1
+	}
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+func protoOrDefault(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
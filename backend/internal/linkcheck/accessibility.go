@@ -0,0 +1,209 @@
+package linkcheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// genericLinkTexts don't describe where a link goes when read out of context
+var genericLinkTexts = map[string]bool{
+	"click here": true, "click": true, "here": true, "more": true, "read more": true,
+	"learn more": true, "link": true, "this link": true, "continue": true, "details": true,
+}
+
+// AuditAccessibility runs WCAG-style checks over the document. Every issue
+// carries the CSS selector of the offending element.
+func AuditAccessibility(doc *goquery.Document) []Issue {
+	var issues []Issue
+	add := func(s *goquery.Selection, severity, code, message string) {
+		issue := Issue{Category: "accessibility", Severity: severity, Code: code, Message: message}
+		if s != nil {
+			issue.Selector = selectorPath(s)
+		}
+		issues = append(issues, issue)
+	}
+
+	if strings.TrimSpace(doc.Find("html").AttrOr("lang", "")) == "" {
+		add(doc.Find("html"), SeverityError, "lang_missing", "The html element has no lang attribute")
+	}
+
+	if strings.TrimSpace(doc.Find("title").First().Text()) == "" {
+		add(doc.Find("head"), SeverityError, "title_missing", "The page has no title to identify it to assistive technology")
+	}
+
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		if _, ok := s.Attr("alt"); ok || isPresentational(s) {
+			return
+		}
+		add(s, SeverityError, "img_alt_missing", fmt.Sprintf("Image %s has no alt text", s.AttrOr("src", "")))
+	})
+
+	doc.Find("input, select, textarea").Each(func(i int, s *goquery.Selection) {
+		switch strings.ToLower(s.AttrOr("type", "")) {
+		case "hidden", "submit", "reset", "button":
+			return
+		case "image":
+			if strings.TrimSpace(s.AttrOr("alt", "")) == "" && !hasAriaName(s) {
+				add(s, SeverityError, "button_name_missing", "Image button has no alt text")
+			}
+			return
+		}
+		if !hasLabel(doc, s) {
+			add(s, SeverityError, "input_label_missing", "Form field has no label")
+		}
+	})
+
+	doc.Find(`button, [role="button"], input[type="button"]`).Each(func(i int, s *goquery.Selection) {
+		if accessibleText(s) == "" && strings.TrimSpace(s.AttrOr("value", "")) == "" {
+			add(s, SeverityError, "button_name_missing", "Button has no accessible name")
+		}
+	})
+
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		text := accessibleText(s)
+		href := s.AttrOr("href", "")
+		switch {
+		case text == "":
+			add(s, SeverityError, "link_text_missing", fmt.Sprintf("Link to %s has no discernible text", href))
+		case genericLinkTexts[strings.ToLower(strings.Trim(text, " .!>»→"))]:
+			add(s, SeverityWarning, "link_text_generic", fmt.Sprintf("Link text %q doesn't describe its target %s", text, href))
+		}
+	})
+
+	ids := make(map[string]int)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		id := s.AttrOr("id", "")
+		ids[id]++
+		if ids[id] == 2 {
+			add(s, SeverityWarning, "duplicate_id", fmt.Sprintf("ID %q is used more than once", id))
+		}
+	})
+
+	doc.Find("[tabindex]").Each(func(i int, s *goquery.Selection) {
+		if n, err := strconv.Atoi(strings.TrimSpace(s.AttrOr("tabindex", ""))); err == nil && n > 0 {
+			add(s, SeverityWarning, "tabindex_positive", fmt.Sprintf("Positive tabindex %d changes the natural focus order", n))
+		}
+	})
+
+	return issues
+}
+
+func isPresentational(s *goquery.Selection) bool {
+	role := strings.ToLower(s.AttrOr("role", ""))
+	return role == "presentation" || role == "none" || s.AttrOr("aria-hidden", "") == "true"
+}
+
+func hasAriaName(s *goquery.Selection) bool {
+	return strings.TrimSpace(s.AttrOr("aria-label", "")) != "" ||
+		strings.TrimSpace(s.AttrOr("aria-labelledby", "")) != "" ||
+		strings.TrimSpace(s.AttrOr("title", "")) != ""
+}
+
+// hasLabel checks the ways a form field can be labelled: <label for>, a
+// wrapping <label>, aria-label(ledby) or title
+func hasLabel(doc *goquery.Document, s *goquery.Selection) bool {
+	if hasAriaName(s) || s.ParentsFiltered("label").Length() > 0 {
+		return true
+	}
+	id := s.AttrOr("id", "")
+	if id == "" {
+		return false
+	}
+	found := false
+	doc.Find("label[for]").EachWithBreak(func(i int, label *goquery.Selection) bool {
+		found = label.AttrOr("for", "") == id
+		return !found
+	})
+	return found
+}
+
+// accessibleText approximates the accessible name of an element: its ARIA
+// label, visible text or the alt text of images inside it
+func accessibleText(s *goquery.Selection) string {
+	if label := strings.TrimSpace(s.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+	if _, ok := s.Attr("aria-labelledby"); ok {
+		return "aria-labelledby"
+	}
+	if text := strings.Join(strings.Fields(s.Text()), " "); text != "" {
+		return text
+	}
+	alt := ""
+	s.Find("img[alt], svg title").EachWithBreak(func(i int, child *goquery.Selection) bool {
+		alt = strings.TrimSpace(child.AttrOr("alt", child.Text()))
+		return alt == ""
+	})
+	if alt != "" {
+		return alt
+	}
+	return strings.TrimSpace(s.AttrOr("title", ""))
+}
+
+// selectorPath builds a CSS selector that uniquely points at s, anchored on
+// the closest ancestor with an ID no other element of the document uses
+func selectorPath(s *goquery.Selection) string {
+	var parts []string
+	for node := s; node.Length() > 0 && goquery.NodeName(node) != "#document"; node = node.Parent() {
+		name := goquery.NodeName(node)
+		if id := node.AttrOr("id", ""); id != "" && uniqueID(node, id) {
+			parts = append(parts, name+"#"+cssEscape(id))
+			break
+		}
+
+		// nth-of-type is only needed when the parent has siblings of the same tag
+		if siblings := node.Parent().ChildrenFiltered(name); siblings.Length() > 1 {
+			name = fmt.Sprintf("%s:nth-of-type(%d)", name, siblings.IndexOfNode(node.Get(0))+1)
+		}
+		parts = append(parts, name)
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// uniqueID reports whether id belongs to a single element of the document s is part of
+func uniqueID(s *goquery.Selection, id string) bool {
+	root := s.Parents().Last()
+	if root.Length() == 0 {
+		root = s
+	}
+	count := 0
+	root.Find("[id]").AddSelection(root.Filter("[id]")).Each(func(i int, e *goquery.Selection) {
+		if e.AttrOr("id", "") == id {
+			count++
+		}
+	})
+	return count == 1
+}
+
+// cssEscape serializes an identifier the way CSS.escape does, so IDs starting
+// with a digit or containing spaces, dots or colons still form a valid selector
+func cssEscape(ident string) string {
+	var sb strings.Builder
+	runes := []rune(ident)
+	for i, r := range runes {
+		switch {
+		case r == 0:
+			sb.WriteRune('\uFFFD')
+		case r < 0x20 || r == 0x7F,
+			i == 0 && r >= '0' && r <= '9',
+			i == 1 && r >= '0' && r <= '9' && runes[0] == '-':
+			fmt.Fprintf(&sb, "\\%x ", r)
+		case i == 0 && r == '-' && len(runes) == 1:
+			sb.WriteString("\\-")
+		case r >= 0x80 || r == '-' || r == '_' ||
+			(r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuditAccessibility(t *testing.T) {
+	type finding struct{ Code, Selector string }
+
+	tests := []struct {
+		name string
+		html string
+		want []finding
+	}{
+		{
+			name: "accessible page",
+			html: `<html lang="en"><head><title>Home</title></head><body>
+				<img src="a.png" alt="Logo"><label for="q">Search</label><input id="q">
+				<button>Go</button><a href="/about">About us</a></body></html>`,
+		},
+		{
+			name: "missing lang and title",
+			html: `<html><head></head><body></body></html>`,
+			want: []finding{{"lang_missing", "html"}, {"title_missing", "html > head"}},
+		},
+		{
+			name: "unnamed elements",
+			html: `<html lang="en"><head><title>Home</title></head><body>
+				<img src="a.png"><img src="b.png" role="presentation">
+				<input name="email"><button></button>
+				<a href="/more">Read more</a><a href="/x"></a></body></html>`,
+			want: []finding{
+				{"img_alt_missing", "html > body > img:nth-of-type(1)"},
+				{"input_label_missing", "html > body > input"},
+				{"button_name_missing", "html > body > button"},
+				{"link_text_generic", "html > body > a:nth-of-type(1)"},
+				{"link_text_missing", "html > body > a:nth-of-type(2)"},
+			},
+		},
+		{
+			name: "duplicate id and positive tabindex",
+			html: `<html lang="en"><head><title>Home</title></head><body>
+				<div id="main" tabindex="2"></div><div id="main"></div></body></html>`,
+			want: []finding{
+				{"duplicate_id", "html > body > div:nth-of-type(2)"},
+				{"tabindex_positive", "html > body > div:nth-of-type(1)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []finding
+			for _, issue := range AuditAccessibility(parseHTML(t, tt.html)) {
+				if issue.Category != "accessibility" {
+					t.Errorf("issue %s has category %q", issue.Code, issue.Category)
+				}
+				got = append(got, finding{issue.Code, issue.Selector})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorPath(t *testing.T) {
+	doc := parseHTML(t, `<html><body>
+		<div id="main"><p>One</p><p id="target-1">Two</p></div>
+		<ul id="dup"><li>a</li></ul><ul id="dup"><li>b</li></ul>
+		<section id="1st"><span>x</span></section>
+		<section id="a.b:c d"><span>y</span></section>
+		</body></html>`)
+
+	tests := []struct {
+		find string
+		want string
+	}{
+		{"#main p:last-child", "p#target-1"},
+		{"#main p:first-child", "div#main > p:nth-of-type(1)"},
+		{"ul:last-of-type li", "html > body > ul:nth-of-type(2) > li"},
+		{"section:first-of-type span", `section#\31 st > span`},
+		{"section:last-of-type span", `section#a\.b\:c\ d > span`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			s := doc.Find(tt.find)
+			got := selectorPath(s)
+			if got != tt.want {
+				t.Errorf("selectorPath() = %q, want %q", got, tt.want)
+			}
+			if matched := doc.Find(got); matched.Length() != 1 || matched.Get(0) != s.Get(0) {
+				t.Errorf("selector %q matches %d elements, want only the original one", got, matched.Length())
+			}
+		})
+	}
+}
+
+func TestCSSEscape(t *testing.T) {
+	tests := map[string]string{
+		"main":    "main",
+		"nav_top": "nav_top",
+		"1st":     `\31 st`,
+		"-2":      `-\32 `,
+		"-":       `\-`,
+		"a.b":     `a\.b`,
+		"a b":     `a\ b`,
+		"ünïcode": "ünïcode",
+		"tab\tid": `tab\9 id`,
+	}
+	for in, want := range tests {
+		if got := cssEscape(in); got != want {
+			t.Errorf("cssEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package linkcheck
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// ContentStats describes the visible text of a page. The readability scores
+// use the English Flesch formulas and are only indicative for other languages.
+type ContentStats struct {
+	Text               string
+	WordCount          int
+	SentenceCount      int
+	TextRatio          float64 // visible text bytes per HTML byte
+	ReadingEase        float64 // Flesch reading ease, higher is easier
+	ReadingGrade       float64 // Flesch-Kincaid grade level
+	DetectedLanguage   string
+	DeclaredLanguage   string
+	LanguageConfidence float64
+}
+
+// skippedTextElements never contribute visible text
+var skippedTextElements = map[string]bool{
+	"script": true, "style": true, "nav": true, "noscript": true, "template": true,
+	"svg": true, "head": true, "iframe": true, "object": true,
+}
+
+// blockElements separate words, inline elements don't
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true, "figure": true,
+	"footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true,
+	"h6": true, "header": true, "hr": true, "li": true, "main": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+	"option": true, "button": true, "label": true,
+}
+
+// stopwords are the most frequent words of each language, enough to tell
+// languages apart on a page worth of text
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "that", "for", "it", "with", "as", "was", "on", "are", "be", "this", "by", "you", "or", "have", "from", "not", "but", "they", "which", "we", "can", "an", "will", "your"},
+	"de": {"der", "die", "und", "in", "den", "von", "zu", "das", "mit", "sich", "des", "auf", "für", "ist", "im", "dem", "nicht", "ein", "eine", "als", "auch", "es", "an", "werden", "aus", "er", "hat", "dass", "sie", "wir"},
+	"fr": {"le", "la", "les", "de", "des", "et", "en", "un", "une", "du", "est", "que", "qui", "dans", "pour", "pas", "sur", "au", "avec", "ce", "il", "par", "plus", "sont", "nous", "vous", "ou", "mais", "aux", "cette"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "del", "se", "las", "por", "un", "para", "con", "no", "una", "su", "al", "es", "lo", "como", "más", "pero", "sus", "le", "ya", "o", "fue", "este", "muy"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "in", "non", "sono", "una", "del", "della", "si", "le", "con", "da", "ha", "gli", "al", "anche", "come", "ma", "questo", "nel", "dei", "alla", "più", "essere", "delle"},
+	"pt": {"de", "que", "não", "o", "a", "do", "da", "em", "um", "para", "com", "uma", "os", "no", "se", "na", "por", "mais", "as", "dos", "como", "mas", "ao", "ele", "das", "à", "seu", "sua", "ou", "quando"},
+	"nl": {"de", "het", "een", "en", "van", "ik", "te", "dat", "die", "in", "is", "niet", "op", "met", "zijn", "voor", "er", "aan", "ook", "als", "maar", "om", "dan", "door", "bij", "nog", "wordt", "worden", "naar", "uit"},
+	"tr": {"ve", "bir", "bu", "da", "de", "için", "ile", "çok", "ne", "daha", "gibi", "olarak", "ama", "en", "o", "mi", "var", "kadar", "sonra", "olan", "ben", "değil", "her", "şey", "ya", "veya", "göre", "ise", "nasıl", "biz"},
+}
+
+var stopwordSets = buildStopwordSets()
+
+func buildStopwordSets() map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(stopwords))
+	for lang, words := range stopwords {
+		sets[lang] = make(map[string]bool, len(words))
+		for _, w := range words {
+			sets[lang][w] = true
+		}
+	}
+	return sets
+}
+
+// AnalyzeContent extracts the visible body text of the page and computes its
+// word count, text-to-HTML ratio, readability and language. htmlSize is the
+// size of the HTML the document was parsed from.
+func AnalyzeContent(doc *goquery.Document, htmlSize int) ContentStats {
+	stats := ContentStats{
+		Text:             ExtractVisibleText(doc),
+		DeclaredLanguage: strings.TrimSpace(doc.Find("html").AttrOr("lang", "")),
+	}
+	if htmlSize > 0 {
+		stats.TextRatio = round(float64(len(stats.Text))/float64(htmlSize), 4)
+	}
+
+	words := splitWords(stats.Text)
+	stats.WordCount = len(words)
+	if stats.WordCount == 0 {
+		return stats
+	}
+
+	stats.SentenceCount = countSentences(stats.Text)
+	syllables := 0
+	for _, w := range words {
+		syllables += countSyllables(w)
+	}
+	wordsPerSentence := float64(stats.WordCount) / float64(stats.SentenceCount)
+	syllablesPerWord := float64(syllables) / float64(stats.WordCount)
+	stats.ReadingEase = round(206.835-1.015*wordsPerSentence-84.6*syllablesPerWord, 1)
+	stats.ReadingGrade = round(0.39*wordsPerSentence+11.8*syllablesPerWord-15.59, 1)
+
+	stats.DetectedLanguage, stats.LanguageConfidence = detectLanguage(words)
+	return stats
+}
+
+// Issues flags a detected language that differs from the declared one
+func (s ContentStats) Issues(pageURL string) []Issue {
+	declared := strings.ToLower(strings.SplitN(s.DeclaredLanguage, "-", 2)[0])
+	if s.DetectedLanguage == "" || declared == "" || declared == s.DetectedLanguage {
+		return nil
+	}
+	return []Issue{{
+		Category: "content",
+		Severity: SeverityWarning,
+		Code:     "language_mismatch",
+		Message:  fmt.Sprintf("Page declares lang %q but its text looks like %q", s.DeclaredLanguage, s.DetectedLanguage),
+		Target:   pageURL,
+	}}
+}
+
+// ExtractVisibleText returns the text of the body, leaving out scripts, styles
+// and navigation, with whitespace collapsed
+func ExtractVisibleText(doc *goquery.Document) string {
+	return visibleText(doc.Find("body"), nil)
+}
+
+// ExtractMainText returns the text of the page's main content: the <main>
+// or <article> element when there is one, otherwise the body without its
+// header, footer and sidebars
+func ExtractMainText(doc *goquery.Document) string {
+	if main := doc.Find(`main, [role="main"]`).First(); main.Length() > 0 {
+		return visibleText(main, nil)
+	}
+	if article := doc.Find("article").First(); article.Length() > 0 {
+		return visibleText(article, nil)
+	}
+	return visibleText(doc.Find("body"), map[string]bool{"header": true, "footer": true, "aside": true})
+}
+
+// visibleText walks the selection collecting text, skipping invisible
+// elements and any in skip
+func visibleText(sel *goquery.Selection, skip map[string]bool) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if skippedTextElements[n.Data] || skip[n.Data] || hasAttr(n, "hidden") {
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if block {
+			sb.WriteByte(' ')
+		}
+	}
+
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func hasAttr(n *html.Node, name string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// countSentences counts runs of sentence-ending punctuation. Text without any still is one sentence.
+func countSentences(text string) int {
+	count := 0
+	inTerminator := false
+	for _, r := range text {
+		terminator := r == '.' || r == '!' || r == '?'
+		if terminator && !inTerminator {
+			count++
+		}
+		inTerminator = terminator
+	}
+	if !inTerminator {
+		// The last sentence has no terminator
+		count++
+	}
+	return count
+}
+
+// countSyllables approximates syllables as groups of vowels, ignoring a silent final e
+func countSyllables(word string) int {
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouyàáâäèéêëìíîïòóôöùúûüı", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+	if count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// detectLanguage picks the language whose stopwords are the most frequent in
+// the text. Short texts or texts with too few stopwords stay undetected.
+func detectLanguage(words []string) (string, float64) {
+	const minMatches = 5
+
+	scores := make(map[string]int, len(stopwordSets))
+	total := 0
+	for _, w := range words {
+		for lang, set := range stopwordSets {
+			if set[w] {
+				scores[lang]++
+				total++
+			}
+		}
+	}
+
+	best, bestScore := "", 0
+	for lang, score := range scores {
+		if score > bestScore || (score == bestScore && lang < best) {
+			best, bestScore = lang, score
+		}
+	}
+	if bestScore < minMatches {
+		return "", 0
+	}
+	return best, round(float64(bestScore)/float64(total), 2)
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
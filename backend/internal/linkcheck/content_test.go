@@ -0,0 +1,122 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractVisibleText(t *testing.T) {
+	doc := parseHTML(t, `<html><head><title>Ignored</title></head><body>
+		<nav>Menu</nav>
+		<h1>Hello</h1><p>Some <b>bold</b>text.</p>
+		<script>var x = 1;</script><style>p {}</style>
+		<div hidden>Secret</div>
+		<ul><li>One</li><li>Two</li></ul>
+		</body></html>`)
+
+	if got, want := ExtractVisibleText(doc), "Hello Some boldtext. One Two"; got != want {
+		t.Errorf("ExtractVisibleText() = %q, want %q", got, want)
+	}
+}
+
+func TestCountSentences(t *testing.T) {
+	tests := map[string]int{
+		"One sentence.":                1,
+		"No terminator":                1,
+		"First. Second! Third?":        3,
+		"Wait... what?! Really":        3,
+		"Trailing text after. The end": 2,
+	}
+	for text, want := range tests {
+		if got := countSentences(text); got != want {
+			t.Errorf("countSentences(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := map[string]int{
+		"cat":        1,
+		"make":       1,
+		"table":      2,
+		"reading":    2,
+		"beautiful":  3,
+		"rhythm":     1,
+		"university": 5,
+	}
+	for word, want := range tests {
+		if got := countSyllables(word); got != want {
+			t.Errorf("countSyllables(%q) = %d, want %d", word, got, want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"english", "The cat sat on the mat and it was happy with the food that you gave to the cat.", "en"},
+		{"german", "Der Hund und die Katze sind in dem Haus, das ist nicht für sie, aber es ist auch schön.", "de"},
+		{"turkish", "Bu ev çok güzel ve bir bahçe ile daha büyük gibi olan bir yer için ne var ama değil.", "tr"},
+		{"too short", "The end of the story.", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, confidence := detectLanguage(splitWords(tt.text))
+			if got != tt.want {
+				t.Errorf("detectLanguage() = %q (%.2f), want %q", got, confidence, tt.want)
+			}
+			if (got == "") != (confidence == 0) {
+				t.Errorf("confidence %.2f doesn't match language %q", confidence, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeContent(t *testing.T) {
+	html := `<html lang="en"><body><p>The cat sat on the mat. It was a very good cat.</p></body></html>`
+	stats := AnalyzeContent(parseHTML(t, html), len(html))
+
+	if stats.WordCount != 12 || stats.SentenceCount != 2 {
+		t.Errorf("got %d words in %d sentences, want 12 in 2", stats.WordCount, stats.SentenceCount)
+	}
+	// 12 words, 2 sentences and 13 syllables
+	if stats.ReadingEase != 109.1 || stats.ReadingGrade != -0.5 {
+		t.Errorf("reading ease %.1f and grade %.1f, want 109.1 and -0.5", stats.ReadingEase, stats.ReadingGrade)
+	}
+	if stats.TextRatio <= 0 || stats.TextRatio >= 1 {
+		t.Errorf("text ratio = %v, want between 0 and 1", stats.TextRatio)
+	}
+
+	empty := AnalyzeContent(parseHTML(t, "<body></body>"), 13)
+	if empty.WordCount != 0 || empty.ReadingEase != 0 || empty.DetectedLanguage != "" {
+		t.Errorf("empty page stats = %+v, want zero values", empty)
+	}
+}
+
+func TestContentStatsIssues(t *testing.T) {
+	tests := []struct {
+		declared, detected string
+		want               []string
+	}{
+		{"en-US", "en", nil},
+		{"EN", "en", nil},
+		{"de", "en", []string{"language_mismatch"}},
+		{"", "en", nil},
+		{"de", "", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		stats := ContentStats{DeclaredLanguage: tt.declared, DetectedLanguage: tt.detected}
+		for _, issue := range stats.Issues("https://example.com/") {
+			got = append(got, issue.Code)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("declared %q detected %q: got %v, want %v", tt.declared, tt.detected, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package linkcheck
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// shingleSize is how many consecutive words make up one SimHash feature
+const shingleSize = 3
+
+// ContentHash is the SHA-256 of the normalized text, equal for pages whose
+// text only differs in case, punctuation or whitespace
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(strings.Join(splitWords(text), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimHash fingerprints the text so that similar texts get fingerprints with
+// a small Hamming distance. Features are overlapping word shingles.
+func SimHash(text string) uint64 {
+	words := splitWords(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	addFeature := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	if len(words) < shingleSize {
+		addFeature(strings.Join(words, " "))
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		addFeature(strings.Join(words[i:i+shingleSize], " "))
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// HammingDistance counts the bits that differ between two fingerprints
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
@@ -0,0 +1,89 @@
+package linkcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+const article = `The crawler visits every page of a site, reads its main content and
+stores a fingerprint of the text. Pages with the same fingerprint are exact
+duplicates, while pages whose fingerprints differ in only a few bits are near
+duplicates, such as the same article with a different date, a changed
+navigation menu or a short note added at the end. Grouping these pages helps
+site owners find thin or repeated content that search engines may ignore, and
+decide which version should be the canonical one for their visitors.`
+
+func TestHammingDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0b1010, 0b0101, 4},
+		{^uint64(0), 0, 64},
+		{1 << 63, 1, 2},
+	}
+	for _, tt := range tests {
+		if got := HammingDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("HammingDistance(%b, %b) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimHashDistance(t *testing.T) {
+	tests := []struct {
+		name        string
+		other       string
+		sameHash    bool
+		maxDistance int
+		minDistance int
+	}{
+		{name: "identical", other: article, sameHash: true},
+		{
+			name:     "case, punctuation and whitespace",
+			other:    strings.ToUpper(strings.ReplaceAll(article, ",", " ;  ")),
+			sameHash: true,
+		},
+		{
+			name:        "one word changed",
+			other:       strings.Replace(article, "different date", "different title", 1),
+			maxDistance: 12,
+		},
+		{
+			name:        "sentence appended",
+			other:       article + " Thanks for reading.",
+			maxDistance: 12,
+		},
+		{
+			name:        "unrelated text",
+			other:       "Our bakery sells fresh bread every morning from six until noon, closed on Sundays and public holidays.",
+			minDistance: 20,
+		},
+	}
+
+	base := SimHash(article)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentHash(tt.other) == ContentHash(article); got != tt.sameHash {
+				t.Errorf("same content hash = %v, want %v", got, tt.sameHash)
+			}
+			d := HammingDistance(base, SimHash(tt.other))
+			if tt.sameHash && d != 0 {
+				t.Errorf("distance = %d, want 0", d)
+			}
+			if tt.maxDistance > 0 && d > tt.maxDistance {
+				t.Errorf("distance = %d, want at most %d", d, tt.maxDistance)
+			}
+			if d < tt.minDistance {
+				t.Errorf("distance = %d, want at least %d", d, tt.minDistance)
+			}
+		})
+	}
+}
+
+func TestSimHashEmpty(t *testing.T) {
+	if got := SimHash("  ,. "); got != 0 {
+		t.Errorf("SimHash of text without words = %x, want 0", got)
+	}
+}
@@ -0,0 +1,183 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Form kinds
+const (
+	FormLogin          = "login"
+	FormSignup         = "signup"
+	FormPasswordChange = "password_change"
+	FormSearch         = "search"
+	FormContact        = "contact"
+	FormNewsletter     = "newsletter"
+	FormPayment        = "payment"
+	FormOther          = "other"
+)
+
+var (
+	csrfFieldPattern    = regexp.MustCompile(`(?i)csrf|xsrf|^_token$|authenticity_token|requestverificationtoken|^nonce$`)
+	paymentFieldPattern = regexp.MustCompile(`(?i)^cc-|card.?(number|num|no)|cvv|cvc|security.?code|expir|iban`)
+	searchFieldPattern  = regexp.MustCompile(`(?i)^(q|s|query|search|keywords?|term)$`)
+	currentPassPattern  = regexp.MustCompile(`(?i)old|current`)
+	newsletterPattern   = regexp.MustCompile(`(?i)newsletter|subscribe|mailing`)
+)
+
+// Form describes a <form> on the page
+type Form struct {
+	Kind              string
+	Method            string
+	Action            string
+	ActionHTTPS       bool
+	HasCSRFToken      bool
+	Autocomplete      string
+	FieldAutocomplete []string
+	PasswordFields    int
+	Selector          string
+}
+
+// formFields summarizes the inputs of a form for classification
+type formFields struct {
+	passwords     int
+	newPassword   bool
+	currentPass   bool
+	email         bool
+	search        bool
+	payment       bool
+	textarea      bool
+	visibleFields int
+	csrf          bool
+	autocomplete  []string
+}
+
+// AnalyzeForms classifies every form on the page and flags password forms
+// submitting over plain HTTP or via GET
+func AnalyzeForms(doc *goquery.Document, pageURL string) ([]Form, []Issue) {
+	var forms []Form
+	var issues []Issue
+
+	doc.Find("form").Each(func(i int, s *goquery.Selection) {
+		fields := inspectFormFields(s)
+
+		form := Form{
+			Kind:              classifyForm(s, fields),
+			Method:            formMethod(s),
+			Action:            resolveURL(pageURL, strings.TrimSpace(s.AttrOr("action", ""))),
+			HasCSRFToken:      fields.csrf,
+			Autocomplete:      strings.TrimSpace(s.AttrOr("autocomplete", "")),
+			FieldAutocomplete: fields.autocomplete,
+			PasswordFields:    fields.passwords,
+			Selector:          selectorPath(s),
+		}
+		if action, err := url.Parse(form.Action); err == nil {
+			form.ActionHTTPS = action.Scheme == "https"
+		}
+		forms = append(forms, form)
+
+		if form.PasswordFields == 0 {
+			return
+		}
+		if !form.ActionHTTPS {
+			issues = append(issues, Issue{
+				Category: "forms",
+				Severity: SeverityError,
+				Code:     "password_form_insecure",
+				Message:  fmt.Sprintf("%s form submits a password over plain HTTP to %s", form.Kind, form.Action),
+				Target:   pageURL,
+				Selector: form.Selector,
+			})
+		}
+		if form.Method == "GET" {
+			issues = append(issues, Issue{
+				Category: "forms",
+				Severity: SeverityError,
+				Code:     "password_form_get",
+				Message:  fmt.Sprintf("%s form submits a password via GET, exposing it in the URL", form.Kind),
+				Target:   pageURL,
+				Selector: form.Selector,
+			})
+		}
+	})
+
+	return forms, issues
+}
+
+// formMethod is the method a form submits with. Like browsers, an empty or
+// unknown method attribute means GET.
+func formMethod(s *goquery.Selection) string {
+	switch method := strings.ToUpper(strings.TrimSpace(s.AttrOr("method", ""))); method {
+	case "POST", "DIALOG":
+		return method
+	default:
+		return "GET"
+	}
+}
+
+func inspectFormFields(form *goquery.Selection) formFields {
+	var fields formFields
+
+	form.Find("input, select, textarea").Each(func(i int, s *goquery.Selection) {
+		fieldType := strings.ToLower(s.AttrOr("type", "text"))
+		name := strings.ToLower(s.AttrOr("name", "") + " " + s.AttrOr("id", ""))
+		autocomplete := strings.ToLower(strings.TrimSpace(s.AttrOr("autocomplete", "")))
+		if autocomplete != "" {
+			fields.autocomplete = append(fields.autocomplete, autocomplete)
+		}
+
+		if fieldType == "hidden" {
+			fields.csrf = fields.csrf || csrfFieldPattern.MatchString(s.AttrOr("name", ""))
+			return
+		}
+		if fieldType == "submit" || fieldType == "button" || fieldType == "reset" || fieldType == "image" {
+			return
+		}
+		fields.visibleFields++
+
+		switch {
+		case goquery.NodeName(s) == "textarea":
+			fields.textarea = true
+		case fieldType == "password":
+			fields.passwords++
+			fields.newPassword = fields.newPassword || autocomplete == "new-password"
+			fields.currentPass = fields.currentPass || autocomplete == "current-password" || currentPassPattern.MatchString(name)
+		case fieldType == "email" || autocomplete == "email" || strings.Contains(name, "email"):
+			fields.email = true
+		case fieldType == "search" || searchFieldPattern.MatchString(s.AttrOr("name", "")):
+			fields.search = true
+		}
+
+		if paymentFieldPattern.MatchString(autocomplete) || paymentFieldPattern.MatchString(s.AttrOr("name", "")) {
+			fields.payment = true
+		}
+	})
+
+	return fields
+}
+
+// classifyForm guesses what a form is for from its fields
+func classifyForm(form *goquery.Selection, fields formFields) string {
+	switch {
+	case fields.payment:
+		return FormPayment
+	case fields.passwords >= 2 && fields.currentPass:
+		return FormPasswordChange
+	case fields.passwords >= 2 || (fields.passwords == 1 && fields.newPassword):
+		return FormSignup
+	case fields.passwords == 1:
+		return FormLogin
+	case fields.search || form.AttrOr("role", "") == "search":
+		return FormSearch
+	case fields.textarea:
+		return FormContact
+	case fields.email && (fields.visibleFields <= 2 ||
+		newsletterPattern.MatchString(form.AttrOr("class", "")+" "+form.AttrOr("id", "")+" "+form.AttrOr("action", ""))):
+		return FormNewsletter
+	}
+	return FormOther
+}
@@ -0,0 +1,121 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyForm(t *testing.T) {
+	tests := []struct {
+		name string
+		form string
+		want string
+	}{
+		{"login", `<form><input name="user"><input type="password" name="pass"></form>`, FormLogin},
+		{"signup", `<form><input type="email" name="email"><input type="password" name="pw"><input type="password" name="pw2"></form>`, FormSignup},
+		{"signup with new-password", `<form><input name="user"><input type="password" autocomplete="new-password"></form>`, FormSignup},
+		{"password change", `<form><input type="password" name="old_password"><input type="password" name="new_password"></form>`, FormPasswordChange},
+		{"payment", `<form><input name="cardnumber"><input name="cvc"><input type="password" name="pin"></form>`, FormPayment},
+		{"search by field", `<form><input name="q"><button>Go</button></form>`, FormSearch},
+		{"search by role", `<form role="search"><input name="text"></form>`, FormSearch},
+		{"contact", `<form><input name="name"><input type="email" name="email"><textarea name="message"></textarea></form>`, FormContact},
+		{"newsletter", `<form><input type="email" name="email"><input type="submit"></form>`, FormNewsletter},
+		{"newsletter by class", `<form class="newsletter-signup"><input name="name"><input name="company"><input type="email" name="email"></form>`, FormNewsletter},
+		{"other", `<form><input name="name"><input name="company"><input type="email" name="email"></form>`, FormOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, _ := AnalyzeForms(parseHTML(t, tt.form), "https://example.com/")
+			if len(forms) != 1 {
+				t.Fatalf("got %d forms, want 1", len(forms))
+			}
+			if forms[0].Kind != tt.want {
+				t.Errorf("kind = %q, want %q", forms[0].Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeForms(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       string
+		form       string
+		wantCSRF   bool
+		wantHTTPS  bool
+		wantIssues []string
+	}{
+		{
+			name:      "secure login with CSRF token",
+			page:      "https://example.com/login",
+			form:      `<form method="post" action="/session"><input type="hidden" name="csrf_token" value="x"><input type="password" name="pass"></form>`,
+			wantCSRF:  true,
+			wantHTTPS: true,
+		},
+		{
+			name:       "login over HTTP",
+			page:       "http://example.com/login",
+			form:       `<form method="POST"><input type="password" name="pass"></form>`,
+			wantIssues: []string{"password_form_insecure"},
+		},
+		{
+			name:       "login via GET to an HTTP action",
+			page:       "https://example.com/login",
+			form:       `<form action="http://example.com/session"><input type="password" name="pass"></form>`,
+			wantIssues: []string{"password_form_insecure", "password_form_get"},
+		},
+		{
+			name:       "login with an empty method",
+			page:       "https://example.com/login",
+			form:       `<form method="" action="/session"><input type="password" name="pass"></form>`,
+			wantHTTPS:  true,
+			wantIssues: []string{"password_form_get"},
+		},
+		{
+			name:      "search via GET is fine",
+			page:      "https://example.com/",
+			form:      `<form action="/search"><input name="q"></form>`,
+			wantHTTPS: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms, issues := AnalyzeForms(parseHTML(t, tt.form), tt.page)
+			if len(forms) != 1 {
+				t.Fatalf("got %d forms, want 1", len(forms))
+			}
+			if forms[0].HasCSRFToken != tt.wantCSRF || forms[0].ActionHTTPS != tt.wantHTTPS {
+				t.Errorf("form = %+v, want CSRF %v and HTTPS %v", forms[0], tt.wantCSRF, tt.wantHTTPS)
+			}
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tt.wantIssues) {
+				t.Errorf("issues = %v, want %v", got, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestFormMethod(t *testing.T) {
+	tests := []struct {
+		form string
+		want string
+	}{
+		{`<form>`, "GET"},
+		{`<form method="">`, "GET"},
+		{`<form method=" post ">`, "POST"},
+		{`<form method="dialog">`, "DIALOG"},
+		{`<form method="PUT">`, "GET"},
+	}
+	for _, tt := range tests {
+		forms, _ := AnalyzeForms(parseHTML(t, tt.form+`<input type="password"></form>`), "https://example.com/")
+		if len(forms) != 1 || forms[0].Method != tt.want {
+			t.Errorf("%s: got %+v, want method %s", tt.form, forms, tt.want)
+		}
+	}
+}
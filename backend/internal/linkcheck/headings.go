@@ -0,0 +1,71 @@
+package linkcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Heading is an entry of the document outline
+type Heading struct {
+	Level int
+	Text  string
+}
+
+// ExtractHeadings returns the h1-h6 elements in document order
+func ExtractHeadings(doc *goquery.Document) []Heading {
+	var headings []Heading
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+		headings = append(headings, Heading{
+			Level: int(goquery.NodeName(s)[1] - '0'),
+			Text:  strings.Join(strings.Fields(s.Text()), " "),
+		})
+	})
+	return headings
+}
+
+// AnalyzeHeadings flags a missing or repeated h1, skipped levels, empty
+// headings and headings sharing the same text
+func AnalyzeHeadings(headings []Heading, pageURL string) []Issue {
+	var issues []Issue
+	add := func(severity, code, message string) {
+		issues = append(issues, Issue{Category: "headings", Severity: severity, Code: code, Message: message, Target: pageURL})
+	}
+
+	h1Count := 0
+	for _, h := range headings {
+		if h.Level == 1 {
+			h1Count++
+		}
+	}
+	switch {
+	case h1Count == 0:
+		add(SeverityError, "h1_missing", "Page has no h1 heading")
+	case h1Count > 1:
+		add(SeverityWarning, "h1_multiple", fmt.Sprintf("Page has %d h1 headings", h1Count))
+	}
+
+	seen := make(map[string]int)
+	for i, h := range headings {
+		if i > 0 {
+			prev := headings[i-1]
+			if h.Level > prev.Level+1 {
+				add(SeverityWarning, "heading_skipped_level", fmt.Sprintf("Heading level skipped from h%d to h%d at %q", prev.Level, h.Level, h.Text))
+			}
+		}
+
+		if h.Text == "" {
+			add(SeverityWarning, "heading_empty", fmt.Sprintf("Heading %d (h%d) is empty", i+1, h.Level))
+			continue
+		}
+
+		key := strings.ToLower(h.Text)
+		seen[key]++
+		if seen[key] == 2 {
+			add(SeverityInfo, "heading_duplicate", fmt.Sprintf("Heading text %q is used more than once", h.Text))
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,65 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractHeadings(t *testing.T) {
+	doc := parseHTML(t, `<body><h1> Main
+		title </h1><div><h3>Nested <em>part</em></h3></div><h2></h2></body>`)
+
+	want := []Heading{{1, "Main title"}, {3, "Nested part"}, {2, ""}}
+	if got := ExtractHeadings(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractHeadings() = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeHeadings(t *testing.T) {
+	tests := []struct {
+		name     string
+		headings []Heading
+		want     []string
+	}{
+		{
+			name:     "valid outline",
+			headings: []Heading{{1, "Title"}, {2, "Section"}, {3, "Sub"}, {2, "Other"}},
+		},
+		{
+			name: "no headings",
+			want: []string{"h1_missing"},
+		},
+		{
+			name:     "several h1",
+			headings: []Heading{{1, "One"}, {1, "Two"}},
+			want:     []string{"h1_multiple"},
+		},
+		{
+			name:     "skipped level",
+			headings: []Heading{{1, "Title"}, {3, "Deep"}, {4, "Deeper"}},
+			want:     []string{"heading_skipped_level"},
+		},
+		{
+			name:     "empty heading",
+			headings: []Heading{{1, "Title"}, {2, ""}},
+			want:     []string{"heading_empty"},
+		},
+		{
+			name:     "duplicate text reported once",
+			headings: []Heading{{1, "Title"}, {2, "FAQ"}, {2, "faq"}, {2, "Faq"}},
+			want:     []string{"heading_duplicate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range AnalyzeHeadings(tt.headings, "https://example.com/") {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
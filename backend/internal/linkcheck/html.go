@@ -35,6 +35,11 @@ func CountLinks(doc *goquery.Document, baseURL string) (int, int) {
 		// Resolve relative URLs
 		absURL := base.ResolveReference(parsedHref)
 
+		// mailto:, tel:, javascript: etc. are neither internal nor external links
+		if LinkScheme(absURL.String()) != SchemeHTTP {
+			return
+		}
+
 		if absURL.Host == base.Host {
 			internal++
 		} else {
@@ -45,10 +50,12 @@ func CountLinks(doc *goquery.Document, baseURL string) (int, int) {
 	return internal, external
 }
 
+// HasLoginForm reports whether the page has a form classified as a login form.
+// Signup and password change forms have password fields too but don't count.
 func HasLoginForm(doc *goquery.Document) bool {
     found := false
     doc.Find("form").EachWithBreak(func(i int, s *goquery.Selection) bool {
-        if s.Find(`input[type="password"]`).Length() > 0 {
+        if classifyForm(s, inspectFormFields(s)) == FormLogin {
             found = true
             return false
         }
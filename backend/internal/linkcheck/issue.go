@@ -0,0 +1,20 @@
+package linkcheck
+
+// Issue severities, from most to least important
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Issue is a problem found while analyzing a page. Category groups related
+// checks (links, seo, ...), Code is a stable machine-readable identifier,
+// Target points at the offending link or page and Selector at the element.
+type Issue struct {
+	Category string
+	Severity string
+	Code     string
+	Message  string
+	Target   string
+	Selector string
+}
@@ -0,0 +1,94 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Mixed content kinds. Browsers block active mixed content and only warn about passive content.
+const (
+	MixedContentActive  = "mixed_content_active"
+	MixedContentPassive = "mixed_content_passive"
+	MixedContentForm    = "mixed_content_form"
+)
+
+// mixedContentKinds maps the types ExtractAllLinks reports to the kind of
+// mixed content they are. Anchors only navigate and prefetches only warm the
+// cache for a later navigation, so browsers don't treat them as mixed content.
+var mixedContentKinds = map[string]string{
+	ResourceScript:     MixedContentActive,
+	ResourceStylesheet: MixedContentActive,
+	ResourceIframe:     MixedContentActive,
+	ResourceObject:     MixedContentActive,
+	ResourcePreload:    MixedContentActive,
+	ResourceImage:      MixedContentPassive,
+	ResourceMedia:      MixedContentPassive,
+	ResourceIcon:       MixedContentPassive,
+	ResourceCSS:        MixedContentPassive,
+}
+
+// FindMixedContent flags every element of an HTTPS page that loads a resource
+// over plain http://, and every form action that uses it. resources come from
+// ExtractAllLinks and forms from AnalyzeForms. Nothing is reported for pages
+// that aren't HTTPS.
+func FindMixedContent(resources []Resource, forms []Form, pageURL string) []Issue {
+	if page, err := url.Parse(pageURL); err != nil || page.Scheme != "https" {
+		return nil
+	}
+
+	var issues []Issue
+	add := func(code, target, selector string) {
+		severity := SeverityWarning
+		message := fmt.Sprintf("Passive content loaded over HTTP: %s", target)
+		switch code {
+		case MixedContentActive:
+			severity = SeverityError
+			message = fmt.Sprintf("Active content loaded over HTTP is blocked by browsers: %s", target)
+		case MixedContentForm:
+			message = fmt.Sprintf("Form submits over HTTP: %s", target)
+		}
+		issues = append(issues, Issue{
+			Category: "mixed_content",
+			Severity: severity,
+			Code:     code,
+			Message:  message,
+			Target:   target,
+			Selector: selector,
+		})
+	}
+
+	for _, res := range resources {
+		kind, ok := mixedContentKinds[res.Type]
+		if !ok || !isPlainHTTP(res.URL) {
+			continue
+		}
+		for _, selector := range res.Selectors {
+			add(kind, res.URL, selector)
+		}
+	}
+	for _, form := range forms {
+		if isPlainHTTP(form.Action) {
+			add(MixedContentForm, form.Action, form.Selector)
+		}
+	}
+
+	return issues
+}
+
+// CountMixedContent counts the active and passive mixed content among issues
+func CountMixedContent(issues []Issue) (active, passive int) {
+	for _, issue := range issues {
+		switch issue.Code {
+		case MixedContentActive:
+			active++
+		case MixedContentPassive:
+			passive++
+		}
+	}
+	return active, passive
+}
+
+func isPlainHTTP(link string) bool {
+	return strings.HasPrefix(strings.ToLower(link), "http://")
+}
@@ -0,0 +1,113 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindMixedContent(t *testing.T) {
+	type finding struct{ Code, Target string }
+
+	tests := []struct {
+		name string
+		page string
+		body string
+		want []finding
+	}{
+		{
+			name: "active content",
+			page: "https://example.com/",
+			body: `<script src="http://cdn.example.com/app.js"></script>
+				<link rel="stylesheet" href="HTTP://cdn.example.com/site.css">
+				<iframe src="http://widgets.example.com/"></iframe>
+				<object data="http://cdn.example.com/movie.swf"></object>`,
+			want: []finding{
+				{MixedContentActive, "http://cdn.example.com/app.js"},
+				{MixedContentActive, "http://cdn.example.com/site.css"},
+				{MixedContentActive, "http://widgets.example.com/"},
+				{MixedContentActive, "http://cdn.example.com/movie.swf"},
+			},
+		},
+		{
+			name: "passive content",
+			page: "https://example.com/",
+			body: `<img src="http://img.example.com/a.png" srcset="https://img.example.com/b.png 2x, http://img.example.com/c.png 3x">
+				<video src="http://media.example.com/v.mp4" poster="http://img.example.com/poster.png"></video>
+				<div style="background: url('http://img.example.com/bg.png')"></div>`,
+			want: []finding{
+				{MixedContentPassive, "http://img.example.com/a.png"},
+				{MixedContentPassive, "http://img.example.com/c.png"},
+				{MixedContentPassive, "http://media.example.com/v.mp4"},
+				{MixedContentPassive, "http://img.example.com/poster.png"},
+				{MixedContentPassive, "http://img.example.com/bg.png"},
+			},
+		},
+		{
+			name: "preloads are active, prefetches are left alone",
+			page: "https://example.com/",
+			body: `<link rel="preload" as="script" href="http://cdn.example.com/lib.js">
+				<link rel="prefetch" href="http://example.com/next-page">`,
+			want: []finding{{MixedContentActive, "http://cdn.example.com/lib.js"}},
+		},
+		{
+			name: "form action",
+			page: "https://example.com/",
+			body: `<form action="http://example.com/subscribe"></form>`,
+			want: []finding{{MixedContentForm, "http://example.com/subscribe"}},
+		},
+		{
+			name: "secure and relative resources",
+			page: "https://example.com/",
+			body: `<script src="/app.js"></script><img src="//img.example.com/a.png"><a href="http://example.org/">link</a>`,
+		},
+		{
+			name: "plain HTTP page",
+			page: "http://example.com/",
+			body: `<script src="http://cdn.example.com/app.js"></script>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseHTML(t, tt.body)
+			forms, _ := AnalyzeForms(doc, tt.page)
+
+			var got []finding
+			for _, issue := range FindMixedContent(ExtractAllLinks(doc, tt.page), forms, tt.page) {
+				got = append(got, finding{issue.Code, issue.Target})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindMixedContentReportsEveryElement(t *testing.T) {
+	const page = "https://example.com/"
+	doc := parseHTML(t, `<body>
+		<div id="hero"><img src="http://img.example.com/logo.png" srcset="http://img.example.com/logo.png 2x"></div>
+		<footer><img src="http://img.example.com/logo.png"></footer>
+		</body>`)
+
+	var got []string
+	for _, issue := range FindMixedContent(ExtractAllLinks(doc, page), nil, page) {
+		got = append(got, issue.Selector)
+	}
+	want := []string{"div#hero > img", "html > body > footer > img"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("selectors = %v, want %v", got, want)
+	}
+}
+
+func TestCountMixedContent(t *testing.T) {
+	issues := []Issue{
+		{Code: MixedContentActive},
+		{Code: MixedContentPassive},
+		{Code: MixedContentActive},
+		{Code: MixedContentForm},
+	}
+	if active, passive := CountMixedContent(issues); active != 2 || passive != 1 {
+		t.Errorf("CountMixedContent() = %d, %d, want 2, 1", active, passive)
+	}
+}
@@ -0,0 +1,76 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+)
+
+// LongRedirectChain is the number of hops above which a chain is flagged
+var LongRedirectChain = 3
+
+// AnalyzeRedirectChain flags loops, long chains and HTTPS to HTTP downgrades.
+// For links, permanent redirects are reported too since the source page
+// should point at the target directly.
+func AnalyzeRedirectChain(source string, chain fetcher.RedirectChain, isLink bool) []Issue {
+	var issues []Issue
+
+	if chain.Loop {
+		issues = append(issues, Issue{
+			Category: "redirects",
+			Severity: SeverityError,
+			Code:     "redirect_loop",
+			Message:  "Redirect loop detected",
+			Target:   source,
+		})
+	}
+
+	if len(chain.Hops) > LongRedirectChain {
+		issues = append(issues, Issue{
+			Category: "redirects",
+			Severity: SeverityWarning,
+			Code:     "long_redirect_chain",
+			Message:  fmt.Sprintf("Redirect chain has %d hops (more than %d)", len(chain.Hops), LongRedirectChain),
+			Target:   source,
+		})
+	}
+
+	for _, hop := range chain.Hops {
+		from, err := url.Parse(hop.URL)
+		if err != nil {
+			continue
+		}
+		loc, err := url.Parse(hop.Location)
+		if err != nil {
+			continue
+		}
+		to := from.ResolveReference(loc)
+		if from.Scheme == "https" && to.Scheme == "http" {
+			issues = append(issues, Issue{
+				Category: "redirects",
+				Severity: SeverityError,
+				Code:     "https_downgrade",
+				Message:  fmt.Sprintf("Redirect from %s downgrades to insecure %s", from, to),
+				Target:   source,
+			})
+		}
+	}
+
+	if isLink && len(chain.Hops) > 0 && !chain.Loop && isPermanentRedirect(chain.Hops[0].StatusCode) {
+		issues = append(issues, Issue{
+			Category: "redirects",
+			Severity: SeverityInfo,
+			Code:     "permanent_redirect",
+			Message:  fmt.Sprintf("Link is permanently redirected, update it to %s", chain.FinalURL),
+			Target:   source,
+		})
+	}
+
+	return issues
+}
+
+func isPermanentRedirect(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
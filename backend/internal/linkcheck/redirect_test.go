@@ -0,0 +1,91 @@
+package linkcheck
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+)
+
+func TestAnalyzeRedirectChain(t *testing.T) {
+	hop := func(from string, status int, to string) fetcher.RedirectHop {
+		return fetcher.RedirectHop{URL: from, StatusCode: status, Location: to}
+	}
+
+	tests := []struct {
+		name   string
+		chain  fetcher.RedirectChain
+		isLink bool
+		want   []string
+	}{
+		{name: "no redirects", chain: fetcher.RedirectChain{FinalURL: "https://example.com/"}},
+		{
+			name:  "single temporary redirect",
+			chain: fetcher.RedirectChain{Hops: []fetcher.RedirectHop{hop("https://example.com/a", 302, "/b")}},
+		},
+		{
+			name:   "permanent redirect on a link",
+			chain:  fetcher.RedirectChain{Hops: []fetcher.RedirectHop{hop("https://example.com/a", 301, "/b")}, FinalURL: "https://example.com/b"},
+			isLink: true,
+			want:   []string{"permanent_redirect"},
+		},
+		{
+			name:  "permanent redirect on the page",
+			chain: fetcher.RedirectChain{Hops: []fetcher.RedirectHop{hop("https://example.com/a", 308, "/b")}},
+		},
+		{
+			name: "loop",
+			chain: fetcher.RedirectChain{Loop: true, Hops: []fetcher.RedirectHop{
+				hop("https://example.com/a", 301, "/b"),
+				hop("https://example.com/b", 301, "/a"),
+			}},
+			isLink: true,
+			want:   []string{"redirect_loop"},
+		},
+		{
+			name: "long chain",
+			chain: fetcher.RedirectChain{Hops: []fetcher.RedirectHop{
+				hop("https://example.com/1", 302, "/2"),
+				hop("https://example.com/2", 302, "/3"),
+				hop("https://example.com/3", 302, "/4"),
+				hop("https://example.com/4", 302, "/5"),
+			}},
+			want: []string{"long_redirect_chain"},
+		},
+		{
+			name:  "https downgrade",
+			chain: fetcher.RedirectChain{Hops: []fetcher.RedirectHop{hop("https://example.com/", 302, "http://example.com/")}},
+			want:  []string{"https_downgrade"},
+		},
+		{
+			name:  "http upgrade",
+			chain: fetcher.RedirectChain{Hops: []fetcher.RedirectHop{hop("http://example.com/", 302, "https://example.com/")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range AnalyzeRedirectChain("https://example.com/a", tt.chain, tt.isLink) {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRedirectChainThreshold(t *testing.T) {
+	defer func(n int) { LongRedirectChain = n }(LongRedirectChain)
+	LongRedirectChain = 1
+
+	chain := fetcher.RedirectChain{Hops: []fetcher.RedirectHop{
+		{URL: "https://example.com/1", StatusCode: 302, Location: "/2"},
+		{URL: "https://example.com/2", StatusCode: 302, Location: "/3"},
+	}}
+	issues := AnalyzeRedirectChain("https://example.com/1", chain, false)
+	if len(issues) != 1 || issues[0].Code != "long_redirect_chain" {
+		t.Errorf("got %+v, want a long_redirect_chain issue", issues)
+	}
+}
@@ -0,0 +1,184 @@
+package linkcheck
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Resource types reported alongside every extracted link
+const (
+	ResourceAnchor     = "anchor"
+	ResourceImage      = "image"
+	ResourceScript     = "script"
+	ResourceStylesheet = "stylesheet"
+	ResourceIcon       = "icon"
+	ResourcePreload    = "preload"
+	ResourcePrefetch   = "prefetch"
+	ResourceIframe     = "iframe"
+	ResourceMedia      = "media"
+	ResourceObject     = "object"
+	ResourceCSS        = "css"
+)
+
+// Resource is a single URL referenced by the page together with its type
+type Resource struct {
+	URL  string
+	Type string
+	// Selectors are the CSS selectors of every element referencing the URL as this type
+	Selectors []string
+}
+
+var cssURLPattern = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+
+// ExtractAllLinks collects anchors and every subresource referenced by the page.
+// URLs are resolved against baseURL and deduplicated per type.
+func ExtractAllLinks(doc *goquery.Document, baseURL string) []Resource {
+	var resources []Resource
+	type key struct{ url, resourceType string }
+	seen := make(map[key]int)
+
+	add := func(s *goquery.Selection, href, resourceType string) {
+		href = strings.TrimSpace(href)
+		if href == "" || strings.HasPrefix(href, "#") {
+			return
+		}
+		absURL := resolveURL(baseURL, href)
+		if absURL == "" {
+			return
+		}
+		selector := selectorPath(s)
+		k := key{absURL, resourceType}
+		if i, ok := seen[k]; ok {
+			// An element can reference the same URL twice, e.g. in src and srcset
+			if selectors := resources[i].Selectors; selectors[len(selectors)-1] != selector {
+				resources[i].Selectors = append(selectors, selector)
+			}
+			return
+		}
+		seen[k] = len(resources)
+		resources = append(resources, Resource{URL: absURL, Type: resourceType, Selectors: []string{selector}})
+	}
+
+	doc.Find("a[href], area[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		add(s, href, ResourceAnchor)
+	})
+
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		if src, ok := s.Attr("src"); ok {
+			add(s, src, ResourceImage)
+		}
+		if srcset, ok := s.Attr("srcset"); ok {
+			for _, candidate := range parseSrcset(srcset) {
+				add(s, candidate, ResourceImage)
+			}
+		}
+	})
+
+	doc.Find("picture source[srcset]").Each(func(i int, s *goquery.Selection) {
+		srcset, _ := s.Attr("srcset")
+		for _, candidate := range parseSrcset(srcset) {
+			add(s, candidate, ResourceImage)
+		}
+	})
+
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(s, src, ResourceScript)
+	})
+
+	doc.Find("link[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		if resourceType := linkRelType(s.AttrOr("rel", "")); resourceType != "" {
+			add(s, href, resourceType)
+		}
+	})
+
+	doc.Find("iframe[src], frame[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(s, src, ResourceIframe)
+	})
+
+	doc.Find("object[data]").Each(func(i int, s *goquery.Selection) {
+		data, _ := s.Attr("data")
+		add(s, data, ResourceObject)
+	})
+
+	doc.Find("embed[src]").Each(func(i int, s *goquery.Selection) {
+		src, _ := s.Attr("src")
+		add(s, src, ResourceObject)
+	})
+
+	doc.Find("video, audio, source, track").Each(func(i int, s *goquery.Selection) {
+		// picture sources are images and were handled above
+		if goquery.NodeName(s) == "source" && s.Parent().Is("picture") {
+			return
+		}
+		if src, ok := s.Attr("src"); ok {
+			add(s, src, ResourceMedia)
+		}
+		if poster, ok := s.Attr("poster"); ok {
+			add(s, poster, ResourceImage)
+		}
+	})
+
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		style, _ := s.Attr("style")
+		for _, ref := range extractCSSURLs(style) {
+			add(s, ref, ResourceCSS)
+		}
+	})
+
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		for _, ref := range extractCSSURLs(s.Text()) {
+			add(s, ref, ResourceCSS)
+		}
+	})
+
+	return resources
+}
+
+// linkRelType maps the rel attribute of a <link> element to a resource type.
+// Links that don't load anything (canonical, alternate, ...) return "".
+func linkRelType(rel string) string {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		switch r {
+		case "stylesheet":
+			return ResourceStylesheet
+		case "icon", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon":
+			return ResourceIcon
+		case "preload", "modulepreload":
+			return ResourcePreload
+		case "prefetch":
+			return ResourcePrefetch
+		}
+	}
+	return ""
+}
+
+// parseSrcset returns the URLs of every candidate in a srcset attribute
+func parseSrcset(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// extractCSSURLs returns every url() reference in a chunk of CSS, skipping inline data
+func extractCSSURLs(css string) []string {
+	var urls []string
+	for _, match := range cssURLPattern.FindAllStringSubmatch(css, -1) {
+		ref := strings.TrimSpace(match[1])
+		if ref == "" || strings.HasPrefix(strings.ToLower(ref), "data:") {
+			continue
+		}
+		urls = append(urls, ref)
+	}
+	return urls
+}
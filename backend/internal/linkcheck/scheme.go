@@ -0,0 +1,153 @@
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// Link schemes the checker knows how to handle
+const (
+	SchemeHTTP       = "http"
+	SchemeMailto     = "mailto"
+	SchemeTel        = "tel"
+	SchemeJavascript = "javascript"
+	SchemeData       = "data"
+	SchemeOther      = "other"
+)
+
+// MXResolver looks up the mail exchangers of a domain. *net.Resolver satisfies
+// it; tests can plug in a stub instead of hitting DNS.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// LinkScheme classifies a resolved link by its URL scheme
+func LinkScheme(link string) string {
+	scheme, _, found := strings.Cut(strings.TrimSpace(link), ":")
+	if !found {
+		return SchemeOther
+	}
+	switch strings.ToLower(scheme) {
+	case "http", "https":
+		return SchemeHTTP
+	case "mailto":
+		return SchemeMailto
+	case "tel":
+		return SchemeTel
+	case "javascript":
+		return SchemeJavascript
+	case "data":
+		return SchemeData
+	default:
+		return SchemeOther
+	}
+}
+
+// SplitByScheme separates links that can be checked over HTTP from the rest
+func SplitByScheme(links []Resource) (httpLinks, otherLinks []Resource) {
+	for _, l := range links {
+		if LinkScheme(l.URL) == SchemeHTTP {
+			httpLinks = append(httpLinks, l)
+		} else {
+			otherLinks = append(otherLinks, l)
+		}
+	}
+	return httpLinks, otherLinks
+}
+
+// CheckNonHTTPLinks validates mailto and tel links without making HTTP requests.
+// Invalid ones are returned as broken links; javascript: links are reported as
+// issues. MX records are only looked up when resolver is not nil.
+func CheckNonHTTPLinks(ctx context.Context, links []Resource, resolver MXResolver) ([]LinkCheckResult, []Issue) {
+	var broken []LinkCheckResult
+	var issues []Issue
+
+	for _, l := range links {
+		switch LinkScheme(l.URL) {
+		case SchemeMailto:
+			if status := checkMailto(ctx, l.URL, resolver); status != "" {
+				broken = append(broken, LinkCheckResult{URL: l.URL, Type: l.Type, Status: status, Broken: true})
+			}
+		case SchemeTel:
+			if !validTel(l.URL) {
+				broken = append(broken, LinkCheckResult{URL: l.URL, Type: l.Type, Status: "invalid phone number", Broken: true})
+			}
+		case SchemeJavascript:
+			issues = append(issues, Issue{
+				Category: "links",
+				Severity: SeverityWarning,
+				Code:     "javascript_link",
+				Message:  "javascript: links are not crawlable and are inaccessible without scripting; use a button or a real URL",
+				Target:   l.URL,
+			})
+		}
+	}
+
+	return broken, issues
+}
+
+// checkMailto returns "" for a valid mailto link, otherwise the reason it is broken
+func checkMailto(ctx context.Context, link string, resolver MXResolver) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "invalid mailto link"
+	}
+
+	recipients := parsed.Opaque
+	if unescaped, err := url.PathUnescape(recipients); err == nil {
+		recipients = unescaped
+	}
+	// mailto:?to=... is valid too
+	if recipients == "" {
+		recipients = parsed.Query().Get("to")
+	}
+	if strings.TrimSpace(recipients) == "" {
+		return "mailto link without recipient"
+	}
+
+	for _, recipient := range strings.Split(recipients, ",") {
+		addr, err := mail.ParseAddress(strings.TrimSpace(recipient))
+		if err != nil {
+			return fmt.Sprintf("invalid email address %q", recipient)
+		}
+		_, domain, _ := strings.Cut(addr.Address, "@")
+		if !strings.Contains(domain, ".") {
+			return fmt.Sprintf("invalid email domain %q", domain)
+		}
+		if resolver == nil {
+			continue
+		}
+		mx, err := resolver.LookupMX(ctx, domain)
+		if err != nil || len(mx) == 0 {
+			return fmt.Sprintf("no MX record for %s", domain)
+		}
+	}
+
+	return ""
+}
+
+// validTel checks that a tel: link contains a plausible phone number
+func validTel(link string) bool {
+	_, number, _ := strings.Cut(link, ":")
+	number, _, _ = strings.Cut(number, ";")
+	if unescaped, err := url.PathUnescape(number); err == nil {
+		number = unescaped
+	}
+
+	digits := 0
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 3 && digits <= 15
+}
@@ -0,0 +1,92 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// fakeResolver answers MX lookups from a map instead of DNS
+type fakeResolver struct {
+	mx      map[string][]*net.MX
+	queried []string
+}
+
+func (r *fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	r.queried = append(r.queried, name)
+	if mx, ok := r.mx[name]; ok {
+		return mx, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestCheckNonHTTPLinks(t *testing.T) {
+	tests := []struct {
+		name       string
+		link       string
+		wantStatus string // "" when the link isn't broken
+		wantIssue  string
+	}{
+		{name: "mailto with MX", link: "mailto:info@example.com"},
+		{name: "mailto with to param", link: "mailto:?to=info@example.com"},
+		{name: "mailto with several recipients", link: "mailto:a@example.com,b@example.com"},
+		{name: "mailto without MX", link: "mailto:info@nomail.example", wantStatus: "no MX record for nomail.example"},
+		{name: "mailto without recipient", link: "mailto:", wantStatus: "mailto link without recipient"},
+		{name: "mailto with invalid address", link: "mailto:not-an-address", wantStatus: `invalid email address "not-an-address"`},
+		{name: "mailto with dotless domain", link: "mailto:root@localhost", wantStatus: `invalid email domain "localhost"`},
+		{name: "tel", link: "tel:+1-555-0100"},
+		{name: "tel with letters", link: "tel:call-me", wantStatus: "invalid phone number"},
+		{name: "tel too short", link: "tel:12", wantStatus: "invalid phone number"},
+		{name: "javascript", link: "javascript:void(0)", wantIssue: "javascript_link"},
+		{name: "data is left alone", link: "data:text/plain,hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeResolver{mx: map[string][]*net.MX{
+				"example.com": {{Host: "mx.example.com.", Pref: 10}},
+			}}
+			broken, issues := CheckNonHTTPLinks(context.Background(), []Resource{{URL: tt.link, Type: ResourceAnchor}}, resolver)
+
+			var status string
+			if len(broken) > 0 {
+				status = broken[0].Status
+				if !broken[0].Broken || broken[0].Type != ResourceAnchor {
+					t.Errorf("broken result = %+v, want a broken anchor", broken[0])
+				}
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+
+			var code string
+			if len(issues) > 0 {
+				code = issues[0].Code
+			}
+			if code != tt.wantIssue {
+				t.Errorf("issue = %q, want %q", code, tt.wantIssue)
+			}
+		})
+	}
+}
+
+func TestCheckNonHTTPLinksWithoutResolver(t *testing.T) {
+	broken, _ := CheckNonHTTPLinks(context.Background(), []Resource{{URL: "mailto:info@nomail.example"}}, nil)
+	if len(broken) != 0 {
+		t.Errorf("got %+v, want no MX lookup without a resolver", broken)
+	}
+}
+
+func TestCheckNonHTTPLinksLooksUpEachRecipient(t *testing.T) {
+	resolver := &fakeResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mx.example.com."}},
+		"example.org": {{Host: "mx.example.org."}},
+	}}
+	CheckNonHTTPLinks(context.Background(), []Resource{{URL: "mailto:a@example.com,b@example.org"}}, resolver)
+
+	if want := []string{"example.com", "example.org"}; !reflect.DeepEqual(resolver.queried, want) {
+		t.Errorf("queried %v, want %v", resolver.queried, want)
+	}
+}
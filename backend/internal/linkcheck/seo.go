@@ -0,0 +1,166 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Recommended lengths, in characters, for titles and meta descriptions
+const (
+	MinTitleLength       = 30
+	MaxTitleLength       = 60
+	MinDescriptionLength = 70
+	MaxDescriptionLength = 160
+)
+
+// HreflangAlternate is a <link rel="alternate" hreflang="..."> entry
+type HreflangAlternate struct {
+	Lang string
+	URL  string
+}
+
+// SEOData holds the SEO relevant metadata of a page
+type SEOData struct {
+	Title            string
+	TitleCount       int
+	MetaDescription  string
+	DescriptionCount int
+	MetaRobots       string
+	Canonical        string
+	Viewport         string
+	Lang             string
+	Hreflang         []HreflangAlternate
+	OpenGraph        map[string]string
+	TwitterCard      map[string]string
+}
+
+// ExtractSEO reads the SEO metadata from the document. URLs are resolved against pageURL.
+func ExtractSEO(doc *goquery.Document, pageURL string) SEOData {
+	data := SEOData{
+		OpenGraph:   map[string]string{},
+		TwitterCard: map[string]string{},
+	}
+
+	titles := doc.Find("head title")
+	if titles.Length() == 0 {
+		titles = doc.Find("title")
+	}
+	data.TitleCount = titles.Length()
+	data.Title = strings.TrimSpace(titles.First().Text())
+
+	data.Lang = strings.TrimSpace(doc.Find("html").AttrOr("lang", ""))
+
+	doc.Find("meta").Each(func(i int, s *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+		property := strings.ToLower(strings.TrimSpace(s.AttrOr("property", "")))
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+
+		switch {
+		case name == "description":
+			data.DescriptionCount++
+			if data.MetaDescription == "" {
+				data.MetaDescription = content
+			}
+		case name == "robots":
+			data.MetaRobots = content
+		case name == "viewport":
+			data.Viewport = content
+		case strings.HasPrefix(property, "og:"):
+			data.OpenGraph[property] = content
+		// Twitter tags are meant to use name but property is common in the wild
+		case strings.HasPrefix(name, "twitter:"):
+			data.TwitterCard[name] = content
+		case strings.HasPrefix(property, "twitter:"):
+			data.TwitterCard[property] = content
+		}
+	})
+
+	doc.Find("link[rel][href]").Each(func(i int, s *goquery.Selection) {
+		rels := strings.Fields(strings.ToLower(s.AttrOr("rel", "")))
+		href := resolveURL(pageURL, strings.TrimSpace(s.AttrOr("href", "")))
+		for _, rel := range rels {
+			switch rel {
+			case "canonical":
+				if data.Canonical == "" {
+					data.Canonical = href
+				}
+			case "alternate":
+				if lang, ok := s.Attr("hreflang"); ok {
+					data.Hreflang = append(data.Hreflang, HreflangAlternate{Lang: strings.TrimSpace(lang), URL: href})
+				}
+			}
+		}
+	})
+
+	return data
+}
+
+// Issues flags missing or badly sized titles and descriptions, canonicals
+// pointing to another page and pages excluded from indexing
+func (d SEOData) Issues(pageURL string) []Issue {
+	var issues []Issue
+	add := func(severity, code, message string) {
+		issues = append(issues, Issue{Category: "seo", Severity: severity, Code: code, Message: message, Target: pageURL})
+	}
+
+	titleLength := utf8.RuneCountInString(d.Title)
+	switch {
+	case d.Title == "":
+		add(SeverityError, "title_missing", "Page has no title")
+	case titleLength < MinTitleLength || titleLength > MaxTitleLength:
+		add(SeverityWarning, "title_length", fmt.Sprintf("Title is %d characters, recommended %d-%d", titleLength, MinTitleLength, MaxTitleLength))
+	}
+	if d.TitleCount > 1 {
+		add(SeverityWarning, "title_multiple", fmt.Sprintf("Page has %d title elements", d.TitleCount))
+	}
+
+	descriptionLength := utf8.RuneCountInString(d.MetaDescription)
+	switch {
+	case d.MetaDescription == "":
+		add(SeverityWarning, "description_missing", "Page has no meta description")
+	case descriptionLength < MinDescriptionLength || descriptionLength > MaxDescriptionLength:
+		add(SeverityWarning, "description_length", fmt.Sprintf("Meta description is %d characters, recommended %d-%d", descriptionLength, MinDescriptionLength, MaxDescriptionLength))
+	}
+	if d.DescriptionCount > 1 {
+		add(SeverityWarning, "description_multiple", fmt.Sprintf("Page has %d meta descriptions", d.DescriptionCount))
+	}
+
+	if d.Canonical != "" && !sameURL(d.Canonical, pageURL) {
+		add(SeverityWarning, "canonical_elsewhere", fmt.Sprintf("Canonical URL points to %s", d.Canonical))
+	}
+
+	if hasRobotsDirective(d.MetaRobots, "noindex") || hasRobotsDirective(d.MetaRobots, "none") {
+		add(SeverityWarning, "noindex", "Page is excluded from search engines by meta robots")
+	}
+
+	return issues
+}
+
+func hasRobotsDirective(robots, directive string) bool {
+	for _, d := range strings.Split(strings.ToLower(robots), ",") {
+		if strings.TrimSpace(d) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// sameURL compares two absolute URLs ignoring the fragment, host case and a trailing slash
+func sameURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	normalize := func(u *url.URL) string {
+		u.Fragment = ""
+		u.Host = strings.ToLower(u.Host)
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		return u.String()
+	}
+	return normalize(ua) == normalize(ub)
+}
@@ -0,0 +1,117 @@
+package linkcheck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func parseHTML(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("failed to parse HTML: %v", err)
+	}
+	return doc
+}
+
+func TestExtractSEO(t *testing.T) {
+	doc := parseHTML(t, `<html lang="en"><head>
+		<title> Example page </title>
+		<meta name="Description" content="First description">
+		<meta name="description" content="Second description">
+		<meta name="robots" content="noindex, follow">
+		<meta name="viewport" content="width=device-width">
+		<meta property="og:title" content="OG title">
+		<meta name="twitter:card" content="summary">
+		<meta property="twitter:site" content="@example">
+		<link rel="canonical" href="/canonical">
+		<link rel="alternate" hreflang="de" href="https://example.com/de/">
+		</head><body></body></html>`)
+
+	got := ExtractSEO(doc, "https://example.com/page")
+	want := SEOData{
+		Title:            "Example page",
+		TitleCount:       1,
+		MetaDescription:  "First description",
+		DescriptionCount: 2,
+		MetaRobots:       "noindex, follow",
+		Canonical:        "https://example.com/canonical",
+		Viewport:         "width=device-width",
+		Lang:             "en",
+		Hreflang:         []HreflangAlternate{{Lang: "de", URL: "https://example.com/de/"}},
+		OpenGraph:        map[string]string{"og:title": "OG title"},
+		TwitterCard:      map[string]string{"twitter:card": "summary", "twitter:site": "@example"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractSEO() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSEODataIssues(t *testing.T) {
+	const page = "https://example.com/page"
+	title := "A page title that is long enough to pass"
+	description := strings.Repeat("Describes the page. ", 5)
+
+	tests := []struct {
+		name string
+		data SEOData
+		want []string
+	}{
+		{
+			name: "good page",
+			data: SEOData{Title: title, TitleCount: 1, MetaDescription: description, DescriptionCount: 1, Canonical: page + "/"},
+		},
+		{
+			name: "missing title and description",
+			data: SEOData{},
+			want: []string{"title_missing", "description_missing"},
+		},
+		{
+			name: "short title and long description",
+			data: SEOData{Title: "Short", TitleCount: 1, MetaDescription: strings.Repeat("x", MaxDescriptionLength+1), DescriptionCount: 1},
+			want: []string{"title_length", "description_length"},
+		},
+		{
+			name: "duplicate elements",
+			data: SEOData{Title: title, TitleCount: 2, MetaDescription: description, DescriptionCount: 3},
+			want: []string{"title_multiple", "description_multiple"},
+		},
+		{
+			name: "canonical elsewhere and noindex",
+			data: SEOData{Title: title, TitleCount: 1, MetaDescription: description, DescriptionCount: 1, Canonical: "https://example.com/other", MetaRobots: "NONE"},
+			want: []string{"canonical_elsewhere", "noindex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range tt.data.Issues(page) {
+				got = append(got, issue.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://example.com/page", "https://EXAMPLE.com/page/", true},
+		{"https://example.com/page#top", "https://example.com/page", true},
+		{"https://example.com/page", "http://example.com/page", false},
+		{"https://example.com/page?a=1", "https://example.com/page", false},
+	}
+	for _, tt := range tests {
+		if got := sameURL(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameURL(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -1,61 +1,44 @@
 package linkcheck
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
 )
 
-// LinkCheckResult holds the URL and error/status for broken link
+// LinkCheckResult holds the URL, resource type and error/status for a checked link
+// along with the redirects followed to reach it. ContentLength is -1 when the
+// server didn't send one.
 type LinkCheckResult struct {
-	URL    string
-	Status string
+	URL           string
+	Type          string
+	Status        string
+	Broken        bool
+	ContentLength int64
+	Redirects     fetcher.RedirectChain
 }
 
-// CheckBrokenLinks checks given URLs and returns broken ones
-func CheckBrokenLinks(links []string) ([]LinkCheckResult, error) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+// linkCheckTimeout bounds each link request on top of the fetcher's own timeouts
+const linkCheckTimeout = 5 * time.Second
 
+// CheckLinks checks given resources and returns a result for each of them.
+// Credentials attached to ctx are sent to links on the page's own host.
+func CheckLinks(ctx context.Context, f *fetcher.Fetcher, links []Resource) ([]LinkCheckResult, error) {
 	const maxWorkers = 10
-	linksCh := make(chan string)
+	linksCh := make(chan Resource)
 	resultsCh := make(chan LinkCheckResult)
 	var wg sync.WaitGroup
 
 	worker := func() {
 		defer wg.Done()
-		for link := range linksCh {
-			fmt.Println("Checking link:", link)
-			resp, err := client.Head(link)
-			if err != nil || resp.StatusCode >= 400 {
-				if resp != nil {
-					resp.Body.Close()
-				}
-				respGet, errGet := client.Get(link)
-				if errGet != nil || respGet.StatusCode >= 400 {
-					status := "error"
-					if respGet != nil {
-						status = respGet.Status
-						respGet.Body.Close()
-					} else if resp != nil {
-						status = resp.Status
-					} else if errGet != nil {
-						status = errGet.Error()
-					} else if err != nil {
-						status = err.Error()
-					}
-					resultsCh <- LinkCheckResult{URL: link, Status: status}
-					continue
-				}
-				respGet.Body.Close()
-			} else {
-				resp.Body.Close()
-			}
+		for res := range linksCh {
+			fmt.Println("Checking link:", res.URL, "type:", res.Type)
+			resultsCh <- checkLink(ctx, f, res)
 		}
 	}
 
@@ -74,35 +57,66 @@ func CheckBrokenLinks(links []string) ([]LinkCheckResult, error) {
 	}()
 
 	// Collect results
-	var broken []LinkCheckResult
+	var results []LinkCheckResult
 	go func() {
 		wg.Wait()
 		close(resultsCh)
 	}()
 
 	for res := range resultsCh {
-		fmt.Println("Broken link found:", res.URL, "Status:", res.Status)
-		broken = append(broken, res)
+		if res.Broken {
+			fmt.Println("Broken link found:", res.URL, "Type:", res.Type, "Status:", res.Status)
+		} else if len(res.Redirects.Hops) > 0 {
+			fmt.Println("Redirected link found:", res.URL, "Final URL:", res.Redirects.FinalURL)
+		}
+		results = append(results, res)
 	}
 
-	return broken, nil
+	return results, nil
 }
 
-func ExtractAllLinks(doc *goquery.Document, baseURL string) []string {
-	var links []string
+// checkLink requests a link with HEAD, falling back to GET for servers that
+// don't answer HEAD properly
+func checkLink(ctx context.Context, f *fetcher.Fetcher, res Resource) LinkCheckResult {
+	status, ok, size, chain := requestLink(ctx, f, http.MethodHead, res.URL)
+	if !ok {
+		status, ok, size, chain = requestLink(ctx, f, http.MethodGet, res.URL)
+	}
 
-	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists {
-			return
-		}
-		absURL := resolveURL(baseURL, href)
-		if absURL != "" {
-			links = append(links, absURL)
-		}
-	})
+	return LinkCheckResult{
+		URL:           res.URL,
+		Type:          res.Type,
+		Status:        status,
+		Broken:        !ok,
+		ContentLength: size,
+		Redirects:     *chain,
+	}
+}
 
-	return links
+func requestLink(ctx context.Context, f *fetcher.Fetcher, method, link string) (string, bool, int64, *fetcher.RedirectChain) {
+	ctx, cancel := context.WithTimeout(ctx, linkCheckTimeout)
+	defer cancel()
+
+	ctx, chain := fetcher.WithRedirectChain(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return err.Error(), false, -1, chain
+	}
+
+	resp, err := f.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	switch {
+	case chain.Loop:
+		return fetcher.ErrRedirectLoop.Error(), false, -1, chain
+	case err != nil:
+		return err.Error(), false, -1, chain
+	case resp.StatusCode >= 400:
+		return resp.Status, false, resp.ContentLength, chain
+	}
+	return resp.Status, true, resp.ContentLength, chain
 }
 
 func resolveURL(base, href string) string {
@@ -0,0 +1,295 @@
+package linkcheck
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Structured data formats
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRDFa      = "rdfa"
+)
+
+// StructuredEntity is a schema.org item found on the page
+type StructuredEntity struct {
+	Format     string
+	Type       string
+	Properties map[string]any
+}
+
+// structuredRule lists the properties a type must have. Each group in OneOf
+// needs at least one of its properties.
+type structuredRule struct {
+	Required []string   `json:"required"`
+	OneOf    [][]string `json:"one_of"`
+}
+
+//go:embed structured_rules.json
+var structuredRulesJSON []byte
+
+var structuredRules = mustLoadStructuredRules()
+
+func mustLoadStructuredRules() map[string]structuredRule {
+	var rules map[string]structuredRule
+	if err := json.Unmarshal(structuredRulesJSON, &rules); err != nil {
+		panic(fmt.Sprintf("invalid structured data rules: %v", err))
+	}
+	return rules
+}
+
+// ExtractStructuredData collects JSON-LD, Microdata and RDFa entities from the
+// document. JSON-LD blocks that fail to parse are reported as issues.
+func ExtractStructuredData(doc *goquery.Document, pageURL string) ([]StructuredEntity, []Issue) {
+	var entities []StructuredEntity
+	var issues []Issue
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			issues = append(issues, Issue{
+				Category: "structured_data",
+				Severity: SeverityError,
+				Code:     "jsonld_syntax",
+				Message:  fmt.Sprintf("JSON-LD block %d is not valid JSON: %v", i+1, err),
+				Target:   pageURL,
+			})
+			return
+		}
+		entities = append(entities, jsonLDEntities(data)...)
+	})
+
+	// Top-level items only, nested ones end up as property values
+	doc.Find("[itemscope]").Each(func(i int, s *goquery.Selection) {
+		if _, nested := s.Attr("itemprop"); nested {
+			return
+		}
+		entities = append(entities, StructuredEntity{
+			Format:     FormatMicrodata,
+			Type:       schemaType(s.AttrOr("itemtype", "")),
+			Properties: microdataProperties(s),
+		})
+	})
+
+	doc.Find("[typeof]").Each(func(i int, s *goquery.Selection) {
+		if _, nested := s.Attr("property"); nested {
+			return
+		}
+		entities = append(entities, StructuredEntity{
+			Format:     FormatRDFa,
+			Type:       schemaType(s.AttrOr("typeof", "")),
+			Properties: rdfaProperties(s),
+		})
+	})
+
+	return entities, issues
+}
+
+// ValidateStructuredData checks each entity, and the entities nested in it,
+// against the bundled rules for its type
+func ValidateStructuredData(entities []StructuredEntity, pageURL string) []Issue {
+	var issues []Issue
+	for _, e := range entities {
+		issues = append(issues, validateEntity(e.Format, e.Type, e.Properties, pageURL)...)
+	}
+	return issues
+}
+
+func validateEntity(format, entityType string, props map[string]any, pageURL string) []Issue {
+	var issues []Issue
+
+	if rule, ok := structuredRules[entityType]; ok {
+		var missing []string
+		for _, prop := range rule.Required {
+			if !hasProperty(props, prop) {
+				missing = append(missing, prop)
+			}
+		}
+		for _, group := range rule.OneOf {
+			found := false
+			for _, prop := range group {
+				found = found || hasProperty(props, prop)
+			}
+			if !found {
+				missing = append(missing, strings.Join(group, " or "))
+			}
+		}
+		if len(missing) > 0 {
+			issues = append(issues, Issue{
+				Category: "structured_data",
+				Severity: SeverityWarning,
+				Code:     "missing_property",
+				Message:  fmt.Sprintf("%s %s is missing required properties: %s", format, entityType, strings.Join(missing, ", ")),
+				Target:   pageURL,
+			})
+		}
+	}
+
+	for _, value := range props {
+		for _, nested := range nestedObjects(value) {
+			issues = append(issues, validateEntity(format, schemaType(typeOf(nested)), nested, pageURL)...)
+		}
+	}
+
+	return issues
+}
+
+// jsonLDEntities flattens a JSON-LD document: arrays and @graph hold several entities
+func jsonLDEntities(data any) []StructuredEntity {
+	var entities []StructuredEntity
+
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			entities = append(entities, jsonLDEntities(item)...)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			return jsonLDEntities(graph)
+		}
+		entities = append(entities, StructuredEntity{
+			Format:     FormatJSONLD,
+			Type:       schemaType(typeOf(v)),
+			Properties: v,
+		})
+	}
+
+	return entities
+}
+
+// typeOf returns the @type of a JSON-LD object, the first one if it has several
+func typeOf(obj map[string]any) string {
+	switch t := obj["@type"].(type) {
+	case string:
+		return t
+	case []any:
+		if len(t) > 0 {
+			if s, ok := t[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// schemaType strips the vocabulary from a type: https://schema.org/Product
+// and schema:Product both become Product
+func schemaType(t string) string {
+	fields := strings.Fields(t)
+	if len(fields) == 0 {
+		return ""
+	}
+	t = fields[0]
+	if i := strings.LastIndexAny(t, "/:#"); i >= 0 {
+		t = t[i+1:]
+	}
+	return t
+}
+
+func microdataProperties(scope *goquery.Selection) map[string]any {
+	props := map[string]any{}
+	scope.Find("[itemprop]").Each(func(i int, s *goquery.Selection) {
+		if !ownedBy(s, scope, "[itemscope]") {
+			return
+		}
+
+		var value any
+		if _, isItem := s.Attr("itemscope"); isItem {
+			nested := microdataProperties(s)
+			nested["@type"] = schemaType(s.AttrOr("itemtype", ""))
+			value = nested
+		} else {
+			value = elementValue(s)
+		}
+
+		for _, name := range strings.Fields(s.AttrOr("itemprop", "")) {
+			addProperty(props, name, value)
+		}
+	})
+	return props
+}
+
+func rdfaProperties(scope *goquery.Selection) map[string]any {
+	props := map[string]any{}
+	scope.Find("[property]").Each(func(i int, s *goquery.Selection) {
+		if !ownedBy(s, scope, "[typeof]") {
+			return
+		}
+
+		var value any
+		if typeAttr, isItem := s.Attr("typeof"); isItem {
+			nested := rdfaProperties(s)
+			nested["@type"] = schemaType(typeAttr)
+			value = nested
+		} else {
+			value = elementValue(s)
+		}
+
+		for _, name := range strings.Fields(s.AttrOr("property", "")) {
+			addProperty(props, schemaType(name), value)
+		}
+	})
+	return props
+}
+
+// ownedBy reports whether scope is the closest item ancestor of s
+func ownedBy(s, scope *goquery.Selection, itemSelector string) bool {
+	owner := s.ParentsFiltered(itemSelector).First()
+	return owner.Length() > 0 && owner.Get(0) == scope.Get(0)
+}
+
+// elementValue follows the microdata rules for the value of a property element
+func elementValue(s *goquery.Selection) string {
+	for _, attr := range []string{"content", "href", "src", "datetime", "value", "resource"} {
+		if v, ok := s.Attr(attr); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return strings.TrimSpace(s.Text())
+}
+
+// addProperty keeps repeated properties as a list
+func addProperty(props map[string]any, name string, value any) {
+	existing, ok := props[name]
+	if !ok {
+		props[name] = value
+		return
+	}
+	if list, isList := existing.([]any); isList {
+		props[name] = append(list, value)
+		return
+	}
+	props[name] = []any{existing, value}
+}
+
+func hasProperty(props map[string]any, name string) bool {
+	switch v := props[name].(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func nestedObjects(value any) []map[string]any {
+	switch v := value.(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []any:
+		var objects []map[string]any
+		for _, item := range v {
+			objects = append(objects, nestedObjects(item)...)
+		}
+		return objects
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package linkcheck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractStructuredData(t *testing.T) {
+	doc := parseHTML(t, `<html><head>
+		<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+			{"@type": "Organization", "name": "Example", "url": "https://example.com"},
+			{"@type": ["Article", "Thing"], "headline": "News"}
+		]}</script>
+		<script type="application/ld+json">{not json</script>
+		</head><body>
+		<div itemscope itemtype="https://schema.org/Product">
+			<span itemprop="name">Widget</span>
+			<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+				<meta itemprop="price" content="9.99">
+			</div>
+		</div>
+		<div vocab="https://schema.org/" typeof="BreadcrumbList">
+			<span property="itemListElement" typeof="ListItem"><span property="name">Home</span></span>
+		</div>
+		</body></html>`)
+
+	entities, issues := ExtractStructuredData(doc, "https://example.com/")
+
+	type summary struct{ Format, Type string }
+	var got []summary
+	for _, e := range entities {
+		got = append(got, summary{e.Format, e.Type})
+	}
+	want := []summary{
+		{FormatJSONLD, "Organization"},
+		{FormatJSONLD, "Article"},
+		{FormatMicrodata, "Product"},
+		{FormatRDFa, "BreadcrumbList"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("entities = %v, want %v", got, want)
+	}
+
+	if len(issues) != 1 || issues[0].Code != "jsonld_syntax" {
+		t.Errorf("issues = %+v, want one jsonld_syntax issue", issues)
+	}
+
+	offer, ok := entities[2].Properties["offers"].(map[string]any)
+	if !ok || offer["@type"] != "Offer" || offer["price"] != "9.99" {
+		t.Errorf("microdata offers = %#v, want a nested Offer with its price", entities[2].Properties["offers"])
+	}
+	item, ok := entities[3].Properties["itemListElement"].(map[string]any)
+	if !ok || item["@type"] != "ListItem" || item["name"] != "Home" {
+		t.Errorf("RDFa itemListElement = %#v, want a nested ListItem", entities[3].Properties["itemListElement"])
+	}
+}
+
+func TestValidateStructuredData(t *testing.T) {
+	tests := []struct {
+		name   string
+		entity StructuredEntity
+		want   []string // missing properties per issue
+	}{
+		{
+			name:   "complete product",
+			entity: StructuredEntity{Type: "Product", Properties: map[string]any{"name": "Widget", "review": []any{"Great"}}},
+		},
+		{
+			name:   "product without name or offers",
+			entity: StructuredEntity{Type: "Product", Properties: map[string]any{"name": " "}},
+			want:   []string{"name, offers or review or aggregateRating"},
+		},
+		{
+			name: "nested offer without currency",
+			entity: StructuredEntity{Type: "Product", Properties: map[string]any{
+				"name":   "Widget",
+				"offers": map[string]any{"@type": "Offer", "price": "9.99"},
+			}},
+			want: []string{"priceCurrency"},
+		},
+		{
+			name:   "unknown type",
+			entity: StructuredEntity{Type: "Recipe", Properties: map[string]any{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entity.Format = FormatJSONLD
+			issues := ValidateStructuredData([]StructuredEntity{tt.entity}, "https://example.com/")
+
+			var got []string
+			for _, issue := range issues {
+				if issue.Code != "missing_property" {
+					t.Errorf("unexpected issue %+v", issue)
+				}
+				got = append(got, issue.Message[strings.LastIndex(issue.Message, ": ")+2:])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missing = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaType(t *testing.T) {
+	tests := map[string]string{
+		"https://schema.org/Product": "Product",
+		"schema:Offer":               "Offer",
+		"Article BlogPosting":        "Article",
+		"":                           "",
+	}
+	for in, want := range tests {
+		if got := schemaType(in); got != want {
+			t.Errorf("schemaType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
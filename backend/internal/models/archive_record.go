@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ArchiveRecord locates a WARC record written during a crawl run. Source is
+// "page" for the page request and its redirects, "link" for link checks.
+type ArchiveRecord struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CrawlRunID    uint      `gorm:"index" json:"crawl_run_id"`
+	RecordID      string    `gorm:"size:64;index" json:"record_id"`
+	Type          string    `gorm:"size:16" json:"type"`
+	Source        string    `gorm:"size:16" json:"source"`
+	TargetURI     string    `gorm:"type:text" json:"target_uri"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	PayloadDigest string    `gorm:"size:64" json:"payload_digest,omitempty"`
+	Truncated     bool      `json:"truncated"`
+	Filename      string    `json:"filename"`
+	Offset        int64     `json:"offset"`
+	Length        int64     `json:"length"`
+	Date          time.Time `json:"date"`
+}
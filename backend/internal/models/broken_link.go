@@ -1,7 +1,9 @@
 package models
 
 type BrokenLink struct {
-	ID         uint   `gorm:"primaryKey"`
-	URLID      uint		`json:"url_id"`
-	Link       string `json:"link"`
+	ID           uint   `gorm:"primaryKey"`
+	URLID        uint   `json:"url_id"`
+	Link         string `json:"link"`
+	ResourceType string `gorm:"index;size:32" json:"resource_type"`
+	Status       string `json:"status"`
 }
@@ -0,0 +1,8 @@
+package models
+
+// CrawlHAR is the gzipped HAR 1.2 log of the requests made during a crawl run
+type CrawlHAR struct {
+	ID         uint   `gorm:"primaryKey"`
+	CrawlRunID uint   `gorm:"uniqueIndex"`
+	Data       []byte `gorm:"type:longblob"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// CrawlRun is a single crawl of a URL with its timings and page weight.
+// Timings are in milliseconds, sizes in bytes.
+type CrawlRun struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	URLID          uint       `gorm:"index" json:"-"`
+	Status         string     `gorm:"size:16" json:"status"`
+	HTTPStatusCode int        `json:"http_status,omitempty"`
+	FailureReason  string     `gorm:"type:text" json:"failure_reason,omitempty"`
+	StartedAt      time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+	FinalURL       string     `gorm:"type:text" json:"final_url,omitempty"`
+
+	// SnapshotKey is the blob holding the raw HTML of the page, cleared once
+	// the retention policy drops it
+	SnapshotKey         string `gorm:"size:64;index" json:"snapshot_key,omitempty"`
+	SnapshotContentType string `json:"snapshot_content_type,omitempty"`
+
+	DNSMs      int64 `json:"dns_ms"`
+	ConnectMs  int64 `json:"connect_ms"`
+	TLSMs      int64 `json:"tls_ms"`
+	TTFBMs     int64 `json:"ttfb_ms"`
+	DownloadMs int64 `json:"download_ms"`
+	TotalMs    int64 `json:"total_ms"`
+
+	// HTMLSize is the decoded body, TransferSize what was received before decompression
+	HTMLSize        int64  `json:"html_size"`
+	TransferSize    int64  `json:"transfer_size"`
+	ContentEncoding string `gorm:"size:32" json:"content_encoding,omitempty"`
+
+	// Resource sizes add up the Content-Length of each resource, where known
+	ScriptCount     int   `json:"script_count"`
+	ScriptBytes     int64 `json:"script_bytes"`
+	StylesheetCount int   `json:"stylesheet_count"`
+	StylesheetBytes int64 `json:"stylesheet_bytes"`
+	ImageCount      int   `json:"image_count"`
+	ImageBytes      int64 `json:"image_bytes"`
+
+	WordCount        int     `json:"word_count"`
+	TextRatio        float64 `json:"text_ratio"`
+	ReadingEase      float64 `json:"reading_ease"`
+	ReadingGrade     float64 `json:"reading_grade"`
+	DetectedLanguage string  `gorm:"size:8" json:"detected_language,omitempty"`
+
+	Archive []ArchiveRecord `gorm:"foreignKey:CrawlRunID;constraint:OnDelete:CASCADE;" json:"archive,omitempty"`
+	HAR     *CrawlHAR       `gorm:"foreignKey:CrawlRunID;constraint:OnDelete:CASCADE;" json:"-"`
+}
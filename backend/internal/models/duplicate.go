@@ -0,0 +1,16 @@
+package models
+
+// DuplicateCluster is a group of pages on one site with identical or nearly identical content
+type DuplicateCluster struct {
+	Host  string          `json:"host"`
+	Exact bool            `json:"exact"`
+	Pages []DuplicatePage `json:"pages"`
+}
+
+type DuplicatePage struct {
+	ID    uint   `json:"ID"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// Distance is the SimHash distance to the first page of the cluster
+	Distance int `json:"distance"`
+}
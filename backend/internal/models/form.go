@@ -0,0 +1,17 @@
+package models
+
+// Form is a <form> found on a crawled page
+type Form struct {
+	ID                uint     `gorm:"primaryKey" json:"-"`
+	URLID             uint     `gorm:"index" json:"-"`
+	Position          int      `json:"position"`
+	Kind              string   `gorm:"index;size:32" json:"kind"`
+	Method            string   `gorm:"size:16" json:"method"`
+	Action            string   `gorm:"type:text" json:"action"`
+	ActionHTTPS       bool     `json:"action_https"`
+	HasCSRFToken      bool     `json:"has_csrf_token"`
+	Autocomplete      string   `gorm:"size:32" json:"autocomplete,omitempty"`
+	FieldAutocomplete []string `gorm:"serializer:json;type:text" json:"field_autocomplete,omitempty"`
+	PasswordFields    int      `json:"password_fields"`
+	Selector          string   `gorm:"type:text" json:"selector"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Heading is an entry of a crawled page's heading outline
+type Heading struct {
+	ID       uint   `gorm:"primaryKey" json:"-"`
+	URLID    uint   `gorm:"index" json:"-"`
+	Position int    `json:"position"`
+	Level    int    `json:"level"`
+	Text     string `gorm:"type:text" json:"text"`
+}
@@ -0,0 +1,12 @@
+package models
+
+type Issue struct {
+	ID       uint   `gorm:"primaryKey"`
+	URLID    uint   `gorm:"index" json:"url_id"`
+	Category string `gorm:"index;size:32" json:"category"`
+	Severity string `gorm:"size:16" json:"severity"`
+	Code     string `gorm:"size:64" json:"code"`
+	Message  string `json:"message"`
+	Target   string `json:"target,omitempty"`
+	Selector string `gorm:"type:text" json:"selector,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// RedirectChain is the list of redirects followed for the crawled page or one
+// of its links. Source is the URL as requested, ResourceType is "page" for the
+// crawled page itself.
+type RedirectChain struct {
+	ID           uint          `gorm:"primaryKey"`
+	URLID        uint          `gorm:"index" json:"url_id"`
+	Source       string        `json:"source"`
+	ResourceType string        `gorm:"size:32" json:"resource_type"`
+	FinalURL     string        `json:"final_url"`
+	Loop         bool          `json:"loop"`
+	Hops         []RedirectHop `gorm:"foreignKey:RedirectChainID;constraint:OnDelete:CASCADE;" json:"hops"`
+}
+
+type RedirectHop struct {
+	ID              uint   `gorm:"primaryKey"`
+	RedirectChainID uint   `gorm:"index" json:"-"`
+	Position        int    `json:"position"`
+	URL             string `json:"url"`
+	StatusCode      int    `json:"status_code"`
+	Location        string `json:"location"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// SecurityReport holds the security headers, cookies and TLS details of a crawled page
+type SecurityReport struct {
+	ID      uint             `gorm:"primaryKey" json:"-"`
+	URLID   uint             `gorm:"uniqueIndex" json:"-"`
+	Grade   string           `gorm:"size:2" json:"grade"`
+	Headers []SecurityHeader `gorm:"serializer:json;type:text" json:"headers"`
+	Cookies []CookieFlags    `gorm:"serializer:json;type:text" json:"cookies"`
+
+	// TLS details are empty for plain HTTP pages
+	TLSVersion   string     `gorm:"size:16" json:"tls_version,omitempty"`
+	TLSCipher    string     `json:"tls_cipher,omitempty"`
+	CertSubject  string     `gorm:"type:text" json:"cert_subject,omitempty"`
+	CertIssuer   string     `gorm:"type:text" json:"cert_issuer,omitempty"`
+	CertSANs     []string   `gorm:"serializer:json;type:text" json:"cert_sans,omitempty"`
+	CertNotAfter *time.Time `json:"cert_not_after,omitempty"`
+}
+
+// SecurityHeader is one graded response header. Grade is good, weak or missing.
+type SecurityHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	Grade string `json:"grade"`
+	Note  string `json:"note,omitempty"`
+}
+
+type CookieFlags struct {
+	Name     string `json:"name"`
+	Secure   bool   `json:"secure"`
+	HttpOnly bool   `json:"http_only"`
+	SameSite string `json:"same_site,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// SEOMetadata holds the SEO tags found on a crawled page
+type SEOMetadata struct {
+	ID              uint                `gorm:"primaryKey" json:"-"`
+	URLID           uint                `gorm:"uniqueIndex" json:"-"`
+	MetaDescription string              `gorm:"type:text" json:"meta_description"`
+	MetaRobots      string              `json:"meta_robots"`
+	Canonical       string              `gorm:"type:text" json:"canonical"`
+	Viewport        string              `json:"viewport"`
+	Lang            string              `gorm:"size:35" json:"lang"`
+	Hreflang        []HreflangAlternate `gorm:"serializer:json;type:text" json:"hreflang"`
+	OpenGraph       map[string]string   `gorm:"serializer:json;type:text" json:"open_graph"`
+	TwitterCard     map[string]string   `gorm:"serializer:json;type:text" json:"twitter_card"`
+}
+
+type HreflangAlternate struct {
+	Lang string `json:"lang"`
+	URL  string `json:"url"`
+}
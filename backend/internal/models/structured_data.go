@@ -0,0 +1,10 @@
+package models
+
+// StructuredData is a schema.org entity found on a crawled page
+type StructuredData struct {
+	ID         uint           `gorm:"primaryKey" json:"-"`
+	URLID      uint           `gorm:"index" json:"-"`
+	Format     string         `gorm:"size:16" json:"format"`
+	Type       string         `gorm:"index;size:128" json:"type"`
+	Properties map[string]any `gorm:"serializer:json;type:mediumtext" json:"properties"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// Tag is a label that groups URLs
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"uniqueIndex;size:64;not null" json:"name"`
+}
@@ -1,12 +1,17 @@
 package models
 
-import "time"
+import (
+	"net/url"
+	"strings"
+	"time"
+)
 
 type URL struct {
 	ID              uint         `gorm:"primaryKey"`
-	URL             string       `gorm:"unique;not null"`
+	URL             string       `gorm:"unique;not null;index:idx_urls_search,class:FULLTEXT"`
+	Host            string       `gorm:"size:255;index"` // see SiteHost
 	HTMLVersion     string
-	Title           string
+	Title           string       `gorm:"type:longtext;index:idx_urls_search,class:FULLTEXT"`
 	H1Count         int
 	H2Count         int
 	H3Count					int
@@ -17,8 +22,45 @@ type URL struct {
 	ExternalLinks   int
 	BrokenLinks     int
 	LoginFormFound  bool
+	MixedContentCount int
+	MixedContentActive int
+	MixedContentPassive int
+	WordCount       int
+	TextRatio       float64
+	ReadingEase     float64
+	ReadingGrade    float64
+	DetectedLanguage string
+	ContentHash     string `gorm:"size:64;index"`
+	SimHash         uint64
+	SitemapLastMod  *time.Time
 	Status          string
+	FinalURL        string
+	HTTPStatusCode  int
+	FailureReason   string
+	ContentType     string
+	ContentLength   int64
+	Charset         string
+	Credentials     string `gorm:"type:text" json:"-"` // encrypted, see credentials.Encrypt
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	BrokenLinksDetails []BrokenLink `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Issues             []Issue      `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	RedirectChains     []RedirectChain `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	SEO                *SEOMetadata    `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	StructuredData     []StructuredData `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Headings           []Heading        `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Forms              []Form           `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Security           *SecurityReport  `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Runs               []CrawlRun       `gorm:"foreignKey:URLID;constraint:OnDelete:CASCADE;"`
+	Tags               []Tag            `gorm:"many2many:url_tags;constraint:OnDelete:CASCADE;"`
+}
+
+// SiteHost is the lowercased host of a URL without a leading www., which
+// groups the pages of one site
+func SiteHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
 }
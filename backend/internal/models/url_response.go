@@ -3,22 +3,48 @@ package models
 import "time"
 
 type URLResponse struct {
-	ID                uint          `json:"ID"`
-	URL               string        `json:"url"`
-	Status            string        `json:"status"`
-	Title             string        `json:"title"`
-	HTMLVersion       string        `json:"html_version"`
-	H1Count           int           `json:"h1_count"`
-	H2Count           int           `json:"h2_count"`
-	H3Count           int           `json:"h3_count"`
-	H4Count           int           `json:"h4_count"`
-	H5Count           int           `json:"h5_count"`
-	H6Count           int           `json:"h6_count"`
-	InternalLinks     int           `json:"internal_links"`
-	ExternalLinks     int           `json:"external_links"`
-	BrokenLinks       int           `json:"broken_links"`
-	HasLoginForm      bool          `json:"has_login_form"`
-	CreatedAt         time.Time     `json:"created_at"`
+	ID             uint       `json:"ID"`
+	URL            string     `json:"url"`
+	Status         string     `json:"status"`
+	Title          string     `json:"title"`
+	HTMLVersion    string     `json:"html_version"`
+	H1Count        int        `json:"h1_count"`
+	H2Count        int        `json:"h2_count"`
+	H3Count        int        `json:"h3_count"`
+	H4Count        int        `json:"h4_count"`
+	H5Count        int        `json:"h5_count"`
+	H6Count        int        `json:"h6_count"`
+	InternalLinks  int        `json:"internal_links"`
+	ExternalLinks  int        `json:"external_links"`
+	BrokenLinks    int        `json:"broken_links"`
+	HasLoginForm   bool       `json:"has_login_form"`
+	MixedContent   int        `json:"mixed_content_count"`
+	MixedActive    int        `json:"mixed_content_active"`
+	MixedPassive   int        `json:"mixed_content_passive"`
+	HasCredentials bool       `json:"has_credentials"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FinalURL       string     `json:"final_url,omitempty"`
+	HTTPStatus     int        `json:"http_status,omitempty"`
+	FailureReason  string     `json:"failure_reason,omitempty"`
+	ContentType    string     `json:"content_type,omitempty"`
+	ContentLength  int64      `json:"content_length"`
+	Charset        string     `json:"charset,omitempty"`
+	WordCount      int        `json:"word_count"`
+	TextRatio      float64    `json:"text_ratio"`
+	ReadingEase    float64    `json:"reading_ease"`
+	ReadingGrade   float64    `json:"reading_grade"`
+	DetectedLang   string     `json:"detected_language,omitempty"`
+	ContentHash    string     `json:"content_hash,omitempty"`
+	SitemapLastMod *time.Time `json:"sitemap_lastmod,omitempty"`
+	Tags           []string   `json:"tags"`
 
-	BrokenLinksDetails []BrokenLink `json:"broken_links_details,omitempty"`
+	BrokenLinksDetails []BrokenLink                `json:"broken_links_details,omitempty"`
+	BrokenLinksByType  map[string]int              `json:"broken_links_by_type,omitempty"`
+	Issues             []Issue                     `json:"issues,omitempty"`
+	Redirects          []RedirectChain             `json:"redirects,omitempty"`
+	SEO                *SEOMetadata                `json:"seo,omitempty"`
+	StructuredData     map[string][]StructuredData `json:"structured_data,omitempty"`
+	Headings           []Heading                   `json:"headings,omitempty"`
+	Forms              []Form                      `json:"forms,omitempty"`
+	Security           *SecurityReport             `json:"security,omitempty"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestURLCredentialsNotSerialized(t *testing.T) {
+	data, err := json.Marshal(URL{URL: "https://example.com", Credentials: "sealed-credentials"})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "sealed-credentials") || strings.Contains(strings.ToLower(string(data)), `"credentials"`) {
+		t.Errorf("URL JSON includes the credentials: %s", data)
+	}
+}
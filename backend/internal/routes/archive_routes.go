@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/warc"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterArchiveRoutes serves the WARC records archived for crawl runs from dir
+func RegisterArchiveRoutes(r *gin.Engine, db *gorm.DB, dir string) {
+	archiveGroup := r.Group("/")
+	archiveGroup.Use(auth.AuthMiddleware())
+
+	// List the archived records of a run
+	archiveGroup.GET("/url/:id/runs/:run_id/archive", func(c *gin.Context) {
+		run, ok := findRun(c, db)
+		if !ok {
+			return
+		}
+
+		var records []models.ArchiveRecord
+		if err := db.Where("crawl_run_id = ?", run.ID).Order("id").Find(&records).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"run_id": run.ID, "records": records})
+	})
+
+	// Return the page response of a run as it was archived. The final response
+	// of the page is used, after any redirects. With format=warc the WARC record
+	// itself is returned.
+	archiveGroup.GET("/url/:id/runs/:run_id/archive/response", func(c *gin.Context) {
+		if dir == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "WARC archiving is not enabled"})
+			return
+		}
+		run, ok := findRun(c, db)
+		if !ok {
+			return
+		}
+
+		var entry models.ArchiveRecord
+		err := db.Where("crawl_run_id = ? AND source = ? AND type = ?", run.ID, "page", warc.TypeResponse).
+			Order("id DESC").First(&entry).Error
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No archived response for this run"})
+			return
+		}
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		loc := warc.Location{Filename: entry.Filename, Offset: entry.Offset, Length: entry.Length}
+
+		if c.Query("format") == "warc" {
+			data, err := warc.ReadRaw(dir, loc)
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="run-%d.warc.gz"`, run.ID))
+			c.Data(http.StatusOK, "application/warc", data)
+			return
+		}
+
+		record, err := warc.ReadRecord(dir, loc)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		status, header, body, err := record.HTTPResponse()
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		// The page is third-party content, so it must not run with this origin.
+		// The body is passed on as received, so its encoding goes with it.
+		c.Header("Content-Security-Policy", "sandbox")
+		c.Header("X-Content-Type-Options", "nosniff")
+		if enc := header.Get("Content-Encoding"); enc != "" {
+			c.Header("Content-Encoding", enc)
+		}
+		c.Header("X-Archive-Record-ID", record.ID)
+		c.Header("X-Archive-Target-URI", record.TargetURI)
+		c.Header("X-Archive-Date", record.Date.UTC().Format(http.TimeFormat))
+		c.Header("X-Archive-Status", status)
+		if record.Truncated != "" {
+			c.Header("X-Archive-Truncated", record.Truncated)
+		}
+		contentType := header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.Data(http.StatusOK, contentType, body)
+	})
+}
@@ -0,0 +1,482 @@
+package routes
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
+	analyzer "github.com/UmutAkturk14/web-crawler/backend/internal/crawler"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Bulk request limits. Crawls are slow so fewer of them fit in one request.
+const (
+	maxBulkItems     = 1000
+	maxBulkCrawls    = 100
+	bulkCrawlWorkers = 4
+	maxTagLength     = 64
+)
+
+type BulkCreateRequest struct {
+	URLs []string `json:"urls" binding:"required,min=1"`
+}
+
+type BulkIDsRequest struct {
+	IDs []uint `json:"ids" binding:"required,min=1"`
+}
+
+type BulkTagRequest struct {
+	IDs    []uint   `json:"ids" binding:"required,min=1"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// BulkItemResult is the outcome of a bulk operation for one URL
+type BulkItemResult struct {
+	ID     uint   `json:"id,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func RegisterBulkRoutes(r *gin.Engine, db *gorm.DB, f *fetcher.Fetcher) {
+	bulkGroup := r.Group("/urls/bulk")
+	bulkGroup.Use(auth.AuthMiddleware())
+
+	// Accepts {"urls": [...]} or a multipart CSV upload in the "file" field
+	bulkGroup.POST("/create", func(c *gin.Context) {
+		var urls []string
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			file, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV file"})
+				return
+			}
+			src, err := file.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to open CSV file"})
+				return
+			}
+			defer src.Close()
+			if urls, err = readURLsCSV(src); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV: " + err.Error()})
+				return
+			}
+		} else {
+			var req BulkCreateRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input or missing urls field"})
+				return
+			}
+			urls = req.URLs
+		}
+		if len(urls) == 0 || len(urls) > maxBulkItems {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Between 1 and %d URLs are required", maxBulkItems)})
+			return
+		}
+
+		// URLs are unique under the column's case-insensitive collation, so
+		// they're matched by their lowercased form, mapped to their index + 1
+		results := make([]BulkItemResult, len(urls))
+		valid := make(map[string]int)
+		for i, raw := range urls {
+			u := strings.TrimSpace(raw)
+			results[i] = BulkItemResult{URL: u}
+			switch {
+			case !isWebURL(u):
+				results[i].Status, results[i].Error = "invalid", "not an http(s) URL"
+			case valid[strings.ToLower(u)] > 0:
+				results[i].Status, results[i].Error = "invalid", "duplicate in request"
+			default:
+				valid[strings.ToLower(u)] = i + 1
+			}
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			candidates := make([]string, 0, len(valid))
+			for _, i := range valid {
+				candidates = append(candidates, results[i-1].URL)
+			}
+
+			var existing []models.URL
+			if len(candidates) > 0 {
+				if err := tx.Select("id", "url").Where("url IN ?", candidates).Find(&existing).Error; err != nil {
+					return err
+				}
+			}
+			for _, e := range existing {
+				if i := valid[strings.ToLower(e.URL)]; i > 0 {
+					results[i-1].ID, results[i-1].Status = e.ID, "exists"
+					delete(valid, strings.ToLower(e.URL))
+				}
+			}
+
+			if len(valid) == 0 {
+				return nil
+			}
+			newURLs := make([]models.URL, 0, len(valid))
+			pending := make([]string, 0, len(valid))
+			for _, i := range valid {
+				u := results[i-1].URL
+				newURLs = append(newURLs, models.URL{URL: u, Host: models.SiteHost(u), Status: "pending"})
+				pending = append(pending, u)
+			}
+			// Rows the collation still considers duplicates, e.g. differing only in
+			// accents, are skipped instead of failing the whole batch
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&newURLs, 500).Error; err != nil {
+				return err
+			}
+
+			var stored []models.URL
+			if err := tx.Select("id", "url").Where("url IN ?", pending).Find(&stored).Error; err != nil {
+				return err
+			}
+			exact := make(map[string]uint, len(stored))
+			for _, s := range stored {
+				exact[s.URL] = s.ID
+			}
+			for _, s := range stored {
+				i := valid[strings.ToLower(s.URL)]
+				if i == 0 {
+					continue
+				}
+				r := &results[i-1]
+				if id, ok := exact[r.URL]; ok {
+					r.ID, r.Status = id, "created"
+				} else if r.Status == "" {
+					r.ID, r.Status = s.ID, "exists"
+				}
+			}
+			for _, i := range valid {
+				if r := &results[i-1]; r.Status == "" {
+					r.Status, r.Error = "exists", "conflicts with a stored URL"
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, bulkResponse(results, "created", "exists"))
+	})
+
+	bulkGroup.POST("/delete", func(c *gin.Context) {
+		ids, ok := bindBulkIDs(c, maxBulkItems)
+		if !ok {
+			return
+		}
+
+		results := make([]BulkItemResult, len(ids))
+		err := db.Transaction(func(tx *gorm.DB) error {
+			found, err := existingIDs(tx, ids)
+			if err != nil {
+				return err
+			}
+			var toDelete []uint
+			for i, id := range ids {
+				results[i] = BulkItemResult{ID: id, Status: "not_found"}
+				if found[id] {
+					results[i].Status = "deleted"
+					toDelete = append(toDelete, id)
+				}
+			}
+			if len(toDelete) == 0 {
+				return nil
+			}
+			return tx.Delete(&models.URL{}, toDelete).Error
+		})
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, bulkResponse(results, "deleted"))
+	})
+
+	// Crawls run a few at a time and the response waits for all of them. Once
+	// the client goes away no more crawls are started, the running ones finish.
+	bulkGroup.POST("/crawl", func(c *gin.Context) {
+		ids, ok := bindBulkIDs(c, maxBulkCrawls)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		results := make([]BulkItemResult, len(ids))
+		sem := make(chan struct{}, bulkCrawlWorkers)
+		var wg sync.WaitGroup
+		for i, id := range ids {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				results[i] = BulkItemResult{ID: id, Status: "cancelled", Error: ctx.Err().Error()}
+				continue
+			}
+			wg.Add(1)
+			go func(i int, id uint) {
+				defer func() { <-sem; wg.Done() }()
+				results[i] = crawlOne(db, f, id)
+			}(i, id)
+		}
+		wg.Wait()
+
+		c.JSON(http.StatusOK, bulkResponse(results, "done"))
+	})
+
+	bulkGroup.POST("/tag", func(c *gin.Context) {
+		var req BulkTagRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input or missing ids field"})
+			return
+		}
+		if !validBulkIDs(c, req.IDs, maxBulkItems) {
+			return
+		}
+		add, err := normalizeTags(req.Add)
+		if err == nil {
+			req.Remove, err = normalizeTags(req.Remove)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(add) == 0 && len(req.Remove) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to add or remove"})
+			return
+		}
+
+		results := make([]BulkItemResult, len(req.IDs))
+		err = db.Transaction(func(tx *gorm.DB) error {
+			addTags, err := findOrCreateTags(tx, add)
+			if err != nil {
+				return err
+			}
+			var removeTags []models.Tag
+			if len(req.Remove) > 0 {
+				if err := tx.Where("name IN ?", req.Remove).Find(&removeTags).Error; err != nil {
+					return err
+				}
+			}
+
+			found, err := existingIDs(tx, req.IDs)
+			if err != nil {
+				return err
+			}
+			for i, id := range req.IDs {
+				results[i] = BulkItemResult{ID: id, Status: "not_found"}
+				if !found[id] {
+					continue
+				}
+				urlEntry := models.URL{ID: id}
+				if len(addTags) > 0 {
+					if err := tx.Model(&urlEntry).Association("Tags").Append(addTags); err != nil {
+						return err
+					}
+				}
+				if len(removeTags) > 0 {
+					if err := tx.Model(&urlEntry).Association("Tags").Delete(removeTags); err != nil {
+						return err
+					}
+				}
+				results[i].Status = "tagged"
+			}
+			return nil
+		})
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, bulkResponse(results, "tagged"))
+	})
+
+	r.GET("/tags", auth.AuthMiddleware(), func(c *gin.Context) {
+		var tags []struct {
+			Name  string `json:"name"`
+			Count int64  `json:"count"`
+		}
+		if err := db.Table("tags").
+			Select("tags.name, COUNT(url_tags.url_id) AS count").
+			Joins("LEFT JOIN url_tags ON url_tags.tag_id = tags.id").
+			Group("tags.id, tags.name").Order("tags.name").
+			Scan(&tags).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tags": tags})
+	})
+}
+
+// crawlOne crawls a single URL for a bulk request, like POST /crawl/:id does
+func crawlOne(db *gorm.DB, f *fetcher.Fetcher, id uint) BulkItemResult {
+	result := BulkItemResult{ID: id}
+
+	var urlEntry models.URL
+	if err := db.First(&urlEntry, id).Error; err != nil {
+		result.Status = "not_found"
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Status, result.Error = "failed", err.Error()
+		}
+		return result
+	}
+	result.URL = urlEntry.URL
+
+	urlEntry.Status = "crawling"
+	if err := db.Save(&urlEntry).Error; err != nil {
+		result.Status, result.Error = "failed", "Failed to update status"
+		return result
+	}
+
+	if err := analyzer.CrawlURL(db, f, &urlEntry); err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+	result.Status = "done"
+	return result
+}
+
+// bindBulkIDs reads {"ids": [...]}, rejecting duplicates and more than limit IDs
+func bindBulkIDs(c *gin.Context, limit int) ([]uint, bool) {
+	var req BulkIDsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input or missing ids field"})
+		return nil, false
+	}
+	if !validBulkIDs(c, req.IDs, limit) {
+		return nil, false
+	}
+	return req.IDs, true
+}
+
+// validBulkIDs rejects duplicate and zero IDs and more than limit IDs
+func validBulkIDs(c *gin.Context, ids []uint, limit int) bool {
+	if len(ids) > limit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d IDs are allowed", limit)})
+		return false
+	}
+
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid or duplicate ID %d", id)})
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
+// bulkResponse wraps per-item results with the number that ended in one of the ok statuses
+func bulkResponse(results []BulkItemResult, okStatuses ...string) gin.H {
+	succeeded := 0
+	for _, r := range results {
+		for _, s := range okStatuses {
+			if r.Status == s {
+				succeeded++
+				break
+			}
+		}
+	}
+	return gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	}
+}
+
+func existingIDs(tx *gorm.DB, ids []uint) (map[uint]bool, error) {
+	var found []uint
+	if err := tx.Model(&models.URL{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	set := make(map[uint]bool, len(found))
+	for _, id := range found {
+		set[id] = true
+	}
+	return set, nil
+}
+
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		if err := tx.Where(models.Tag{Name: name}).FirstOrCreate(&tags[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// normalizeTags trims and lowercases tag names and drops duplicates
+func normalizeTags(names []string) ([]string, error) {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		if len(name) > maxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", name, maxTagLength)
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	return tags, nil
+}
+
+// readURLsCSV takes the url column of a CSV file, or its first column when
+// there is no header row
+func readURLsCSV(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var urls []string
+	column := 0
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 0 {
+			header := -1
+			for i, field := range record {
+				if strings.EqualFold(strings.TrimSpace(field), "url") {
+					header = i
+				}
+			}
+			if header >= 0 {
+				column = header
+				continue
+			}
+		}
+		if column < len(record) && strings.TrimSpace(record[column]) != "" {
+			urls = append(urls, record[column])
+		}
+	}
+	return urls, nil
+}
+
+func isWebURL(raw string) bool {
+	parsed, err := url.ParseRequestURI(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
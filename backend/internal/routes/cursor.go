@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// urlCursor is the position after the last URL of a page: its sort key and
+// ID. It is handed out base64 encoded and only valid for the same sort.
+type urlCursor struct {
+	Field string          `json:"f"`
+	Order string          `json:"o"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// urlSchema is the parsed models.URL, used to read and decode sort keys generically
+func urlSchema(db *gorm.DB) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&models.URL{}); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// sortValue reads the sort key of u
+func (s urlSort) sortValue(db *gorm.DB, u models.URL) (any, error) {
+	sch, err := urlSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	field := sch.LookUpField(s.Field)
+	if field == nil {
+		return nil, fmt.Errorf("unknown sort field %s", s.Field)
+	}
+	value, _ := field.ValueOf(context.Background(), reflect.ValueOf(&u).Elem())
+	return value, nil
+}
+
+// encodeCursor returns the cursor pointing after u
+func (s urlSort) encodeCursor(db *gorm.DB, u models.URL) (string, error) {
+	value, err := s.sortValue(db, u)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	token, err := json.Marshal(urlCursor{Field: s.Field, Order: s.Order, Value: raw, ID: u.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// applyCursor restricts a sorted query on the urls table to the rows after the cursor
+func (s urlSort) applyCursor(db, q *gorm.DB, token string) (*gorm.DB, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor urlCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errInvalidCursor
+	}
+	if cursor.Field != s.Field || cursor.Order != s.Order {
+		return nil, fmt.Errorf("%w: it was issued for sort_by=%s order=%s", errInvalidCursor, cursor.Field, cursor.Order)
+	}
+
+	sch, err := urlSchema(db)
+	if err != nil {
+		return nil, err
+	}
+	field := sch.LookUpField(s.Field)
+	if field == nil {
+		return nil, errInvalidCursor
+	}
+	value := reflect.New(field.FieldType)
+	if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	return s.applyAfter(q, value.Elem().Interface(), cursor.ID), nil
+}
+
+// applyAfter restricts a sorted query on the urls table to the rows after
+// the one with the given sort key and ID
+func (s urlSort) applyAfter(q *gorm.DB, value any, id uint) *gorm.DB {
+	op := ">"
+	if s.Order == "desc" {
+		op = "<"
+	}
+	column := "urls." + s.Field
+	return q.Where(column+" "+op+" ? OR ("+column+" = ? AND urls.id "+op+" ?)", value, value, id)
+}
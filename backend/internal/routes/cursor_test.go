@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	u := models.URL{ID: 42, URL: "https://example.com/", Title: "Home", BrokenLinks: 3,
+		LoginFormFound: true, TextRatio: 0.25, CreatedAt: created}
+
+	tests := []struct {
+		sort     urlSort
+		wantSQL  string
+		wantVars []any
+	}{
+		{
+			sort:     urlSort{Field: "created_at", Order: "desc"},
+			wantSQL:  "urls.created_at < ? OR (urls.created_at = ? AND urls.id < ?)",
+			wantVars: []any{created, created, uint(42)},
+		},
+		{
+			sort:     urlSort{Field: "title", Order: "asc"},
+			wantSQL:  "urls.title > ? OR (urls.title = ? AND urls.id > ?)",
+			wantVars: []any{"Home", "Home", uint(42)},
+		},
+		{
+			sort:     urlSort{Field: "broken_links", Order: "desc"},
+			wantSQL:  "urls.broken_links < ? OR (urls.broken_links = ? AND urls.id < ?)",
+			wantVars: []any{3, 3, uint(42)},
+		},
+		{
+			sort:     urlSort{Field: "login_form_found", Order: "asc"},
+			wantSQL:  "urls.login_form_found > ? OR (urls.login_form_found = ? AND urls.id > ?)",
+			wantVars: []any{true, true, uint(42)},
+		},
+		{
+			sort:     urlSort{Field: "text_ratio", Order: "asc"},
+			wantSQL:  "urls.text_ratio > ? OR (urls.text_ratio = ? AND urls.id > ?)",
+			wantVars: []any{0.25, 0.25, uint(42)},
+		},
+	}
+
+	db := dryRunDB(t)
+	for _, tt := range tests {
+		t.Run(tt.sort.Field, func(t *testing.T) {
+			token, err := tt.sort.encodeCursor(db, u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := tt.sort.applyCursor(db, db.Model(&models.URL{}), token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := q.Find(&[]models.URL{}).Statement
+
+			want := "SELECT * FROM `urls` WHERE " + tt.wantSQL
+			if got := stmt.SQL.String(); got != want {
+				t.Errorf("SQL = %q, want %q", got, want)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tt.wantVars) {
+				t.Errorf("vars = %#v, want %#v", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
+
+func TestApplyCursorRejects(t *testing.T) {
+	db := dryRunDB(t)
+	sort := urlSort{Field: "created_at", Order: "desc"}
+	otherSort, err := urlSort{Field: "title", Order: "desc"}.encodeCursor(db, models.URL{ID: 1, Title: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherOrder, err := urlSort{Field: "created_at", Order: "asc"}.encodeCursor(db, models.URL{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"not base64":               "!!!",
+		"not JSON":                 "bm90IGpzb24",
+		"issued for another sort":  otherSort,
+		"issued for another order": otherOrder,
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := sort.applyCursor(db, db.Model(&models.URL{}), token); !errors.Is(err, errInvalidCursor) {
+				t.Errorf("err = %v, want errInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/export"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize is how many URLs are loaded and written at a time
+const exportBatchSize = 500
+
+var urlExportColumns = []string{
+	"id", "url", "status", "title", "html_version",
+	"h1_count", "h2_count", "h3_count", "h4_count", "h5_count", "h6_count",
+	"internal_links", "external_links", "broken_links", "has_login_form",
+	"mixed_content_count", "mixed_content_active", "mixed_content_passive",
+	"has_credentials", "created_at", "final_url",
+	"http_status", "failure_reason", "content_type", "content_length", "charset",
+	"word_count", "text_ratio", "reading_ease", "reading_grade",
+	"detected_language", "content_hash", "sitemap_lastmod", "tags",
+}
+
+var brokenLinkExportColumns = []string{"url_id", "url", "link", "resource_type", "status"}
+
+// BrokenLinkExport is a broken link together with the page it was found on
+type BrokenLinkExport struct {
+	URLID        uint   `json:"url_id"`
+	URL          string `json:"url"`
+	Link         string `json:"link"`
+	ResourceType string `json:"resource_type"`
+	Status       string `json:"status"`
+}
+
+func RegisterExportRoutes(r *gin.Engine, db *gorm.DB) {
+	exportGroup := r.Group("/")
+	exportGroup.Use(auth.AuthMiddleware())
+
+	// Export every URL matching the listing filters, in the listing order
+	exportGroup.GET("/urls/export", func(c *gin.Context) {
+		format, filters, sort, ok := parseExportParams(c)
+		if !ok {
+			return
+		}
+		w, ok := startExport(c, format, "urls", urlExportColumns)
+		if !ok {
+			return
+		}
+
+		err := eachURLBatch(db.WithContext(c.Request.Context()), filters, sort, []string{"Tags"}, func(urls []models.URL) error {
+			for _, u := range urls {
+				resp := urlToResponse(u)
+				if err := w.Write(resp, urlExportValues(resp)); err != nil {
+					return err
+				}
+			}
+			return w.Flush()
+		})
+		finishExport(c, w, err)
+	})
+
+	// Export the broken links of every URL matching the listing filters, grouped by URL in the listing order
+	exportGroup.GET("/urls/export/broken-links", func(c *gin.Context) {
+		format, filters, sort, ok := parseExportParams(c)
+		if !ok {
+			return
+		}
+		w, ok := startExport(c, format, "broken-links", brokenLinkExportColumns)
+		if !ok {
+			return
+		}
+
+		ctxDB := db.WithContext(c.Request.Context())
+		err := eachURLBatch(ctxDB, filters, sort, nil, func(urls []models.URL) error {
+			ids := make([]uint, len(urls))
+			for i, u := range urls {
+				ids[i] = u.ID
+			}
+			var links []models.BrokenLink
+			if err := ctxDB.Where("url_id IN ?", ids).Order("url_id, id").Find(&links).Error; err != nil {
+				return err
+			}
+			byURL := make(map[uint][]models.BrokenLink)
+			for _, l := range links {
+				byURL[l.URLID] = append(byURL[l.URLID], l)
+			}
+
+			for _, u := range urls {
+				for _, l := range byURL[u.ID] {
+					row := BrokenLinkExport{URLID: u.ID, URL: u.URL, Link: l.Link, ResourceType: l.ResourceType, Status: l.Status}
+					if err := w.Write(row, []any{row.URLID, row.URL, row.Link, row.ResourceType, row.Status}); err != nil {
+						return err
+					}
+				}
+			}
+			return w.Flush()
+		})
+		finishExport(c, w, err)
+	})
+}
+
+// parseExportParams reads format plus the filters and sorting of the URL listing
+func parseExportParams(c *gin.Context) (export.Format, urlFilters, urlSort, bool) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", urlFilters{}, urlSort{}, false
+	}
+	sort, err := parseURLSort(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort_by or order parameter"})
+		return "", urlFilters{}, urlSort{}, false
+	}
+	filters, err := parseURLFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", urlFilters{}, urlSort{}, false
+	}
+	return format, filters, sort, true
+}
+
+// startExport sends the download headers and starts the file
+func startExport(c *gin.Context, format export.Format, name string, columns []string) (export.Writer, bool) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(c.Writer, format, columns)
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	return w, true
+}
+
+// finishExport completes the file. Once rows have been streamed the status
+// can't change anymore, so a failed export is left unfinished and logged.
+func finishExport(c *gin.Context, w export.Writer, err error) {
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+// eachURLBatch loads the URLs matching the filters batch by batch in sort
+// order, continuing each batch after the last URL of the previous one
+func eachURLBatch(db *gorm.DB, filters urlFilters, sort urlSort, preloads []string, fn func([]models.URL) error) error {
+	var last *models.URL
+	for {
+		query := db
+		for _, p := range preloads {
+			query = query.Preload(p)
+		}
+		query = sort.apply(filters.apply(query)).Limit(exportBatchSize)
+		if last != nil {
+			value, err := sort.sortValue(db, *last)
+			if err != nil {
+				return err
+			}
+			query = sort.applyAfter(query, value, last.ID)
+		}
+
+		var urls []models.URL
+		if err := query.Find(&urls).Error; err != nil {
+			return err
+		}
+		if len(urls) > 0 {
+			if err := fn(urls); err != nil {
+				return err
+			}
+		}
+		if len(urls) < exportBatchSize {
+			return nil
+		}
+		last = &urls[len(urls)-1]
+	}
+}
+
+// urlExportValues are the values of urlExportColumns for a URL
+func urlExportValues(r models.URLResponse) []any {
+	return []any{
+		r.ID, r.URL, r.Status, r.Title, r.HTMLVersion,
+		r.H1Count, r.H2Count, r.H3Count, r.H4Count, r.H5Count, r.H6Count,
+		r.InternalLinks, r.ExternalLinks, r.BrokenLinks, r.HasLoginForm,
+		r.MixedContent, r.MixedActive, r.MixedPassive,
+		r.HasCredentials, r.CreatedAt, r.FinalURL,
+		r.HTTPStatus, r.FailureReason, r.ContentType, r.ContentLength, r.Charset,
+		r.WordCount, r.TextRatio, r.ReadingEase, r.ReadingGrade,
+		r.DetectedLang, r.ContentHash, r.SitemapLastMod, strings.Join(r.Tags, ", "),
+	}
+}
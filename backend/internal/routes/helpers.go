@@ -4,13 +4,15 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/UmutAkturk14/web-crawler/backend/internal/credentials"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type CreateURLRequest struct {
-	URL string `json:"url" binding:"required,url"`
+	URL         string                   `json:"url" binding:"required,url"`
+	Credentials *credentials.Credentials `json:"credentials"`
 }
 
 // Convert a models.URL to models.URLResponse
@@ -31,11 +33,78 @@ func urlToResponse(u models.URL) models.URLResponse {
 		ExternalLinks:      u.ExternalLinks,
 		BrokenLinks:        u.BrokenLinks,
 		HasLoginForm:       u.LoginFormFound,
+		MixedContent:       u.MixedContentCount,
+		MixedActive:        u.MixedContentActive,
+		MixedPassive:       u.MixedContentPassive,
+		WordCount:          u.WordCount,
+		TextRatio:          u.TextRatio,
+		ReadingEase:        u.ReadingEase,
+		ReadingGrade:       u.ReadingGrade,
+		DetectedLang:       u.DetectedLanguage,
+		ContentHash:        u.ContentHash,
+		SitemapLastMod:     u.SitemapLastMod,
+		Tags:               tagNames(u.Tags),
+		HasCredentials:     u.Credentials != "",
 		CreatedAt:          u.CreatedAt,
+		FinalURL:           u.FinalURL,
+		HTTPStatus:         u.HTTPStatusCode,
+		FailureReason:      u.FailureReason,
+		ContentType:        u.ContentType,
+		ContentLength:      u.ContentLength,
+		Charset:            u.Charset,
 		BrokenLinksDetails: u.BrokenLinksDetails,
+		BrokenLinksByType:  countBrokenLinksByType(u.BrokenLinksDetails),
+		Issues:             u.Issues,
+		Redirects:          u.RedirectChains,
+		SEO:                u.SEO,
+		StructuredData:     groupStructuredDataByType(u.StructuredData),
+		Headings:           u.Headings,
+		Forms:              u.Forms,
+		Security:           u.Security,
 	}
 }
 
+// Group broken links by resource type so broken images show up separately from anchors
+func countBrokenLinksByType(links []models.BrokenLink) map[string]int {
+	if len(links) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, l := range links {
+		counts[l.ResourceType]++
+	}
+	return counts
+}
+
+// Group structured data entities by their schema.org type
+func groupStructuredDataByType(entities []models.StructuredData) map[string][]models.StructuredData {
+	if len(entities) == 0 {
+		return nil
+	}
+	grouped := make(map[string][]models.StructuredData)
+	for _, e := range entities {
+		grouped[e.Type] = append(grouped[e.Type], e)
+	}
+	return grouped
+}
+
+// encryptCredentials seals credentials for storage, returning "" when none are set
+func encryptCredentials(creds *credentials.Credentials) (string, error) {
+	if creds == nil || creds.IsEmpty() {
+		return "", nil
+	}
+	return credentials.Encrypt(creds)
+}
+
+// tagNames flattens tags to their names, an empty list when there are none
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
 func parsePaginationParams(c *gin.Context, defaultPage, defaultPageSize int) (page, pageSize int) {
 	page = defaultPage
 	pageSize = defaultPageSize
@@ -58,3 +127,24 @@ func handleError(c *gin.Context, err error) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
+
+// findRun loads the crawl run given by the :run_id of the URL given by :id
+func findRun(c *gin.Context, db *gorm.DB) (*models.CrawlRun, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return nil, false
+	}
+	runID, err := strconv.Atoi(c.Param("run_id"))
+	if err != nil || runID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run ID"})
+		return nil, false
+	}
+
+	var run models.CrawlRun
+	if err := db.Where("id = ? AND url_id = ?", runID, id).First(&run).Error; err != nil {
+		handleError(c, err)
+		return nil, false
+	}
+	return &run, true
+}
@@ -0,0 +1,226 @@
+package routes
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/sitemap"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxCheckedSitemapURLs bounds how many listed pages are requested when check_urls is set
+	maxCheckedSitemapURLs = 1000
+	// sitemapInsertBatchSize is how many new URLs are inserted per statement
+	sitemapInsertBatchSize = 500
+)
+
+type SitemapImportRequest struct {
+	// URL is a site root or a sitemap
+	URL string `json:"url" binding:"required,url"`
+	// ModifiedSince skips entries whose <lastmod> is older. Entries without one are kept.
+	ModifiedSince *time.Time `json:"modified_since"`
+	// CheckURLs requests every listed page to report errors and redirects
+	CheckURLs bool `json:"check_urls"`
+}
+
+type sitemapURLResult struct {
+	URL      string `json:"url"`
+	Status   string `json:"status,omitempty"`
+	FinalURL string `json:"final_url,omitempty"`
+}
+
+func RegisterSitemapRoutes(r *gin.Engine, db *gorm.DB, f *fetcher.Fetcher) {
+	sitemapGroup := r.Group("/")
+	sitemapGroup.Use(auth.AuthMiddleware())
+
+	sitemapGroup.POST("/urls/import/sitemap", func(c *gin.Context) {
+		var req SitemapImportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input or missing URL field"})
+			return
+		}
+		ctx := c.Request.Context()
+
+		sitemapURLs, err := sitemap.Discover(ctx, f, req.URL)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		result := sitemap.Load(ctx, f, sitemapURLs)
+
+		// Dedupe the listed pages and drop anything that isn't a web page
+		var entries []sitemap.Entry
+		var invalid []sitemapURLResult
+		seen := make(map[string]bool)
+		for _, e := range result.Entries {
+			parsed, err := url.Parse(e.URL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				invalid = append(invalid, sitemapURLResult{URL: e.URL, Status: "invalid URL"})
+				continue
+			}
+			key := sitemap.Normalize(e.URL)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if req.ModifiedSince != nil && e.LastMod != nil && e.LastMod.Before(*req.ModifiedSince) {
+				continue
+			}
+			entries = append(entries, e)
+		}
+
+		created, existing, skipped, err := createSitemapURLs(db, entries)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		var urlErrors, urlRedirects []sitemapURLResult
+		checked := 0
+		if req.CheckURLs {
+			var resources []linkcheck.Resource
+			for _, e := range entries {
+				if len(resources) == maxCheckedSitemapURLs {
+					break
+				}
+				resources = append(resources, linkcheck.Resource{URL: e.URL, Type: "page"})
+			}
+			checked = len(resources)
+
+			results, _ := linkcheck.CheckLinks(ctx, f, resources)
+			for _, res := range results {
+				switch {
+				case res.Broken:
+					urlErrors = append(urlErrors, sitemapURLResult{URL: res.URL, Status: res.Status})
+				case len(res.Redirects.Hops) > 0:
+					urlRedirects = append(urlRedirects, sitemapURLResult{URL: res.URL, Status: res.Status, FinalURL: res.Redirects.FinalURL})
+				}
+			}
+		}
+
+		missing, err := pagesMissingFromSitemap(c, db, f, req.URL, result.Entries)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"sitemaps":             result.Sitemaps,
+			"sitemap_errors":       result.Errors,
+			"sitemap_redirects":    result.Redirects,
+			"truncated":            result.Truncated,
+			"found":                len(result.Entries),
+			"created":              created,
+			"existing":             existing,
+			"skipped":              skipped,
+			"invalid_urls":         invalid,
+			"checked":              checked,
+			"url_errors":           urlErrors,
+			"url_redirects":        urlRedirects,
+			"missing_from_sitemap": missing,
+		})
+	})
+}
+
+// createSitemapURLs adds the entries that aren't stored yet as pending URLs.
+// Stored URLs are compared the way entries are deduped, so a trailing slash
+// or a differently cased host doesn't add a page again. Entries the url
+// column's case-insensitive collation still treats as duplicates, such as
+// paths differing only in case, are skipped and returned.
+func createSitemapURLs(db *gorm.DB, entries []sitemap.Entry) (created, existing int, skipped []sitemapURLResult, err error) {
+	hostSet := make(map[string]bool)
+	for _, e := range entries {
+		hostSet[models.SiteHost(e.URL)] = true
+	}
+	hosts := make([]string, 0, len(hostSet))
+	for host := range hostSet {
+		hosts = append(hosts, host)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		known := make(map[string]bool)
+		if len(hosts) > 0 {
+			var stored []string
+			if err := tx.Model(&models.URL{}).Where("host IN ?", hosts).Pluck("url", &stored).Error; err != nil {
+				return err
+			}
+			for _, u := range stored {
+				known[sitemap.Normalize(u)] = true
+			}
+		}
+
+		var newURLs []models.URL
+		for _, e := range entries {
+			if known[sitemap.Normalize(e.URL)] {
+				existing++
+				continue
+			}
+			newURLs = append(newURLs, models.URL{URL: e.URL, Host: models.SiteHost(e.URL), Status: "pending", SitemapLastMod: e.LastMod})
+		}
+
+		for start := 0; start < len(newURLs); start += sitemapInsertBatchSize {
+			batch := newURLs[start:min(start+sitemapInsertBatchSize, len(newURLs))]
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&batch)
+			if result.Error != nil {
+				return result.Error
+			}
+			created += int(result.RowsAffected)
+			if int(result.RowsAffected) == len(batch) {
+				continue
+			}
+
+			// Whatever isn't stored under its exact URL collided with another one
+			urls := make([]string, len(batch))
+			for i, u := range batch {
+				urls[i] = u.URL
+			}
+			var stored []string
+			if err := tx.Model(&models.URL{}).Where("url IN ?", urls).Pluck("url", &stored).Error; err != nil {
+				return err
+			}
+			inserted := make(map[string]bool, len(stored))
+			for _, u := range stored {
+				inserted[u] = true
+			}
+			for _, u := range urls {
+				if !inserted[u] {
+					skipped = append(skipped, sitemapURLResult{URL: u, Status: "conflicts with a stored URL differing only in case"})
+				}
+			}
+		}
+		return nil
+	})
+	return created, existing, skipped, err
+}
+
+// pagesMissingFromSitemap lists pages of the site that aren't in the sitemap:
+// pages linked from the site root and URLs of the site that are already stored
+func pagesMissingFromSitemap(c *gin.Context, db *gorm.DB, f *fetcher.Fetcher, siteURL string, entries []sitemap.Entry) ([]string, error) {
+	parsed, err := url.Parse(siteURL)
+	if err != nil {
+		return nil, err
+	}
+	root := (&url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: "/"}).String()
+
+	pages, err := sitemap.LinkedPages(c.Request.Context(), f, root)
+	if err != nil {
+		// The root page being unreachable is already visible from the stored URLs
+		pages = nil
+	}
+
+	var stored []string
+	if err := db.Model(&models.URL{}).Where("host = ?", models.SiteHost(root)).Pluck("url", &stored).Error; err != nil {
+		return nil, err
+	}
+	pages = append(pages, stored...)
+
+	return sitemap.Missing(entries, pages), nil
+}
@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/blobstore"
+	analyzer "github.com/UmutAkturk14/web-crawler/backend/internal/crawler"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterSnapshotRoutes serves the HTML snapshots of crawl runs from store, which may be nil
+func RegisterSnapshotRoutes(r *gin.Engine, db *gorm.DB, store blobstore.Store) {
+	snapshotGroup := r.Group("/")
+	snapshotGroup.Use(auth.AuthMiddleware())
+
+	// Serve the raw HTML of a run. It is sandboxed so the page's scripts can't
+	// run with this origin.
+	snapshotGroup.GET("/url/:id/runs/:run_id/snapshot", func(c *gin.Context) {
+		run, body, ok := loadSnapshot(c, db, store)
+		if !ok {
+			return
+		}
+
+		etag := `"` + run.SnapshotKey + `"`
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", "private, max-age=31536000, immutable")
+		c.Header("Content-Security-Policy", "sandbox")
+		c.Header("X-Content-Type-Options", "nosniff")
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		contentType := run.SnapshotContentType
+		if contentType == "" {
+			contentType = "text/html"
+		}
+		c.Data(http.StatusOK, contentType, body)
+	})
+
+	// Run the document analyzers again on a run's snapshot and return what
+	// they find now. Nothing is fetched or saved.
+	snapshotGroup.POST("/url/:id/runs/:run_id/reanalyze", func(c *gin.Context) {
+		run, body, ok := loadSnapshot(c, db, store)
+		if !ok {
+			return
+		}
+
+		var urlEntry models.URL
+		if err := db.Preload("Tags").First(&urlEntry, run.URLID).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		analyzed, issues, err := analyzer.ReanalyzeSnapshot(db, urlEntry, *run, body)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		analyzed.Issues = issues
+
+		c.JSON(http.StatusOK, gin.H{
+			"run_id":       run.ID,
+			"snapshot_key": run.SnapshotKey,
+			"analysis":     urlToResponse(*analyzed),
+		})
+	})
+}
+
+// loadSnapshot finds the run from the path and reads its snapshot
+func loadSnapshot(c *gin.Context, db *gorm.DB, store blobstore.Store) (*models.CrawlRun, []byte, bool) {
+	if store == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "HTML snapshots are not enabled"})
+		return nil, nil, false
+	}
+	run, ok := findRun(c, db)
+	if !ok {
+		return nil, nil, false
+	}
+	if run.SnapshotKey == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No snapshot stored for this run"})
+		return nil, nil, false
+	}
+
+	body, err := store.Get(c.Request.Context(), run.SnapshotKey)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No snapshot stored for this run"})
+		return nil, nil, false
+	}
+	if err != nil {
+		handleError(c, err)
+		return nil, nil, false
+	}
+	return run, body, true
+}
@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// minFullTextTerm is InnoDB's default innodb_ft_min_token_size. Shorter
+// search terms aren't in the full-text index and are matched with LIKE.
+const minFullTextTerm = 3
+
+// urlSortFields are the columns the URL listing can be sorted by
+var urlSortFields = map[string]bool{
+	"id": true, "url": true, "status": true, "title": true,
+	"html_version": true, "h1_count": true, "h2_count": true,
+	"h3_count": true, "h4_count": true, "h5_count": true,
+	"h6_count": true, "internal_links": true, "external_links": true,
+	"broken_links": true, "login_form_found": true, "created_at": true,
+	"word_count": true, "text_ratio": true, "reading_ease": true,
+	"reading_grade": true, "detected_language": true,
+}
+
+// urlFilters are the query parameters that narrow down URL listings
+type urlFilters struct {
+	Statuses       []string
+	HTMLVersions   []string
+	HasLoginForm   *bool
+	BrokenLinksMin *int
+	BrokenLinksMax *int
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	Tags           []string
+	Search         string
+}
+
+// urlSort is the order of a URL listing. Ties are broken by ID so pages are stable.
+type urlSort struct {
+	Field string
+	Order string
+}
+
+// parseURLFilters reads status, html_version, has_login_form,
+// broken_links_min/max, created_after/before, tags and q. Lists are comma-separated.
+func parseURLFilters(c *gin.Context) (urlFilters, error) {
+	var f urlFilters
+	var err error
+
+	f.Statuses = splitList(c.Query("status"))
+	f.HTMLVersions = splitList(c.Query("html_version"))
+	f.Tags, err = normalizeTags(splitList(c.Query("tags")))
+	if err != nil {
+		return f, err
+	}
+	f.Search = strings.TrimSpace(c.Query("q"))
+
+	if v := c.Query("has_login_form"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid has_login_form %q", v)
+		}
+		f.HasLoginForm = &b
+	}
+
+	for name, target := range map[string]**int{
+		"broken_links_min": &f.BrokenLinksMin,
+		"broken_links_max": &f.BrokenLinksMax,
+	} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return f, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*target = &n
+		}
+	}
+
+	for name, target := range map[string]**time.Time{
+		"created_after":  &f.CreatedAfter,
+		"created_before": &f.CreatedBefore,
+	} {
+		if v := c.Query(name); v != "" {
+			t, err := parseTimeParam(v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s %q, expected RFC 3339 or YYYY-MM-DD", name, v)
+			}
+			*target = &t
+		}
+	}
+
+	return f, nil
+}
+
+// parseURLSort reads sort_by and order, defaulting to the newest URLs first
+func parseURLSort(c *gin.Context) (urlSort, error) {
+	s := urlSort{Field: c.DefaultQuery("sort_by", "created_at"), Order: c.DefaultQuery("order", "desc")}
+	if !urlSortFields[s.Field] || (s.Order != "asc" && s.Order != "desc") {
+		return s, fmt.Errorf("invalid sort_by or order parameter")
+	}
+	return s, nil
+}
+
+// apply adds the filters to a query on the urls table
+func (f urlFilters) apply(q *gorm.DB) *gorm.DB {
+	if len(f.Statuses) > 0 {
+		q = q.Where("urls.status IN ?", f.Statuses)
+	}
+	if len(f.HTMLVersions) > 0 {
+		q = q.Where("urls.html_version IN ?", f.HTMLVersions)
+	}
+	if f.HasLoginForm != nil {
+		q = q.Where("urls.login_form_found = ?", *f.HasLoginForm)
+	}
+	if f.BrokenLinksMin != nil {
+		q = q.Where("urls.broken_links >= ?", *f.BrokenLinksMin)
+	}
+	if f.BrokenLinksMax != nil {
+		q = q.Where("urls.broken_links <= ?", *f.BrokenLinksMax)
+	}
+	if f.CreatedAfter != nil {
+		q = q.Where("urls.created_at >= ?", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		q = q.Where("urls.created_at < ?", *f.CreatedBefore)
+	}
+	if len(f.Tags) > 0 {
+		// Any of the tags matches
+		q = q.Where("urls.id IN (SELECT url_tags.url_id FROM url_tags JOIN tags ON tags.id = url_tags.tag_id WHERE tags.name IN ?)", f.Tags)
+	}
+	if f.Search != "" {
+		q = applySearch(q, f.Search)
+	}
+	return q
+}
+
+// apply orders a query on the urls table
+func (s urlSort) apply(q *gorm.DB) *gorm.DB {
+	return q.Order("urls." + s.Field + " " + s.Order).Order("urls.id " + s.Order)
+}
+
+// applySearch requires every search term to appear in the URL or title. Terms
+// long enough for the full-text index use it as prefix matches, shorter ones fall back to LIKE.
+func applySearch(q *gorm.DB, search string) *gorm.DB {
+	terms := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var boolean []string
+	for _, term := range terms {
+		if len([]rune(term)) >= minFullTextTerm {
+			boolean = append(boolean, "+"+term+"*")
+			continue
+		}
+		like := "%" + term + "%"
+		q = q.Where("urls.url LIKE ? OR urls.title LIKE ?", like, like)
+	}
+	if len(boolean) > 0 {
+		q = q.Where("MATCH(urls.url, urls.title) AGAINST (? IN BOOLEAN MODE)", strings.Join(boolean, " "))
+	}
+	return q
+}
+
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseTimeParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, time.Local)
+}
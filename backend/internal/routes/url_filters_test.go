@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds statements without a database to run them on
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "user@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// queryContext is a gin context for a GET request with the given query string
+func queryContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/urls?"+query, nil)
+	return c
+}
+
+func TestParseURLFilters(t *testing.T) {
+	c := queryContext("status=done,%20error,&html_version=HTML5&has_login_form=true" +
+		"&broken_links_min=1&broken_links_max=10&created_after=2024-05-01&created_before=2024-06-01T00:00:00Z" +
+		"&tags=News,blog&q=%20pricing%20")
+
+	f, err := parseURLFilters(c)
+	if err != nil {
+		t.Fatalf("parseURLFilters: %v", err)
+	}
+
+	yes, one, ten := true, 1, 10
+	after := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	before := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	want := urlFilters{
+		Statuses:       []string{"done", "error"},
+		HTMLVersions:   []string{"HTML5"},
+		HasLoginForm:   &yes,
+		BrokenLinksMin: &one,
+		BrokenLinksMax: &ten,
+		CreatedAfter:   &after,
+		CreatedBefore:  &before,
+		Tags:           []string{"news", "blog"},
+		Search:         "pricing",
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("parseURLFilters() = %+v, want %+v", f, want)
+	}
+}
+
+func TestParseURLFiltersRejects(t *testing.T) {
+	tests := []string{
+		"has_login_form=maybe",
+		"broken_links_min=-1",
+		"broken_links_max=many",
+		"created_after=yesterday",
+		"created_before=01/06/2024",
+	}
+	for _, query := range tests {
+		if _, err := parseURLFilters(queryContext(query)); err == nil {
+			t.Errorf("parseURLFilters(%q) succeeded, want error", query)
+		}
+	}
+}
+
+func TestParseURLSort(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    urlSort
+		wantErr bool
+	}{
+		{"", urlSort{Field: "created_at", Order: "desc"}, false},
+		{"sort_by=title&order=asc", urlSort{Field: "title", Order: "asc"}, false},
+		{"sort_by=password", urlSort{}, true},
+		{"sort_by=id%3BDROP%20TABLE%20urls", urlSort{}, true},
+		{"order=random", urlSort{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseURLSort(queryContext(tt.query))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseURLSort(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseURLSort(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestApplySearch(t *testing.T) {
+	tests := []struct {
+		search   string
+		wantSQL  string
+		wantVars []any
+	}{
+		{
+			search:   "pricing page",
+			wantSQL:  "MATCH(urls.url, urls.title) AGAINST (? IN BOOLEAN MODE)",
+			wantVars: []any{"+pricing* +page*"},
+		},
+		{
+			search:   "go-lang",
+			wantSQL:  "(urls.url LIKE ? OR urls.title LIKE ?) AND MATCH(urls.url, urls.title) AGAINST (? IN BOOLEAN MODE)",
+			wantVars: []any{"%go%", "%go%", "+lang*"},
+		},
+		{
+			search:   `"+admin* -x`,
+			wantSQL:  "(urls.url LIKE ? OR urls.title LIKE ?) AND MATCH(urls.url, urls.title) AGAINST (? IN BOOLEAN MODE)",
+			wantVars: []any{"%x%", "%x%", "+admin*"},
+		},
+	}
+
+	db := dryRunDB(t)
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			stmt := applySearch(db.Model(&models.URL{}), tt.search).Find(&[]models.URL{}).Statement
+
+			want := "SELECT * FROM `urls` WHERE " + tt.wantSQL
+			if got := stmt.SQL.String(); got != want {
+				t.Errorf("SQL = %q, want %q", got, want)
+			}
+			if !reflect.DeepEqual(stmt.Vars, tt.wantVars) {
+				t.Errorf("vars = %#v, want %#v", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
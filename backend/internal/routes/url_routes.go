@@ -1,18 +1,23 @@
 package routes
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/UmutAkturk14/web-crawler/backend/internal/auth"
 	analyzer "github.com/UmutAkturk14/web-crawler/backend/internal/crawler"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/credentials"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
 	"github.com/UmutAkturk14/web-crawler/backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 
-func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
+func RegisterURLRoutes(r *gin.Engine, db *gorm.DB, f *fetcher.Fetcher) {
 	urlGroup := r.Group("/")
 	urlGroup.Use(auth.AuthMiddleware())
 
@@ -23,7 +28,13 @@ func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
 			return
 		}
 
-		urlEntry := models.URL{URL: req.URL, Status: "pending"}
+		encrypted, err := encryptCredentials(req.Credentials)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt credentials: " + err.Error()})
+			return
+		}
+
+		urlEntry := models.URL{URL: req.URL, Host: models.SiteHost(req.URL), Status: "pending", Credentials: encrypted}
 
 		if err := db.Create(&urlEntry).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save URL"})
@@ -33,95 +44,114 @@ func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
 		c.JSON(http.StatusCreated, urlToResponse(urlEntry))
 	})
 
-	urlGroup.GET("/urls", func(c *gin.Context) {
-		page, pageSize := parsePaginationParams(c, 1, 10)
-
-		var totalCount int64
-		if err := db.Model(&models.URL{}).Count(&totalCount).Error; err != nil {
-			handleError(c, err)
-			return
+	// /urls/sorted predates the filters and is kept as an alias of /urls
+	urlGroup.GET("/urls", listURLs(db))
+	urlGroup.GET("/urls/sorted", listURLs(db))
+
+	urlGroup.GET("/urls/duplicates", func(c *gin.Context) {
+		maxDistance := analyzer.DefaultDuplicateDistance
+		if v := c.Query("max_distance"); v != "" {
+			d, err := strconv.Atoi(v)
+			if err != nil || d < 0 || d > 16 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "max_distance must be between 0 and 16"})
+				return
+			}
+			maxDistance = d
 		}
 
+		query := db.Select("id", "url", "host", "title", "content_hash", "sim_hash").Where("content_hash <> ''")
+		if host := c.Query("host"); host != "" {
+			query = query.Where("host = ?", models.SiteHost("//"+host))
+		}
 		var urls []models.URL
-		offset := (page - 1) * pageSize
-
-		if err := db.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&urls).Error; err != nil {
+		if err := query.Find(&urls).Error; err != nil {
 			handleError(c, err)
 			return
 		}
 
-		response := make([]models.URLResponse, len(urls))
-		for i, u := range urls {
-			response[i] = urlToResponse(u)
-		}
-
+		clusters := analyzer.FindDuplicateClusters(urls, maxDistance)
 		c.JSON(http.StatusOK, gin.H{
-			"page":        page,
-			"page_size":   pageSize,
-			"total_count": totalCount,
-			"urls":        response,
+			"max_distance": maxDistance,
+			"clusters":     clusters,
 		})
 	})
 
-	urlGroup.GET("/urls/sorted", func(c *gin.Context) {
-		sortBy := c.DefaultQuery("sort_by", "created_at")
-		order := c.DefaultQuery("order", "desc")
+	urlGroup.GET("/url/:id", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
 
-		allowedFields := map[string]bool{
-			"id": true, "url": true, "status": true, "title": true,
-			"html_version": true, "h1_count": true, "h2_count": true,
-			"h3_count": true, "h4_count": true, "h5_count": true,
-			"h6_count": true, "internal_links": true, "external_links": true,
-			"broken_links": true, "login_form_found": true, "created_at": true,
+		var urlEntry models.URL
+		if err := db.Preload("BrokenLinksDetails").Preload("Issues").Preload("SEO").Preload("StructuredData").
+			Preload("RedirectChains.Hops", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Preload("Headings", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Preload("Forms", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+			Preload("Security").Preload("Tags").
+			First(&urlEntry, id).Error; err != nil {
+			handleError(c, err)
+			return
 		}
 
-		if !allowedFields[sortBy] || (order != "asc" && order != "desc") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort_by or order parameter"})
+		c.JSON(http.StatusOK, urlToResponse(urlEntry))
+	})
+
+	urlGroup.GET("/url/:id/runs", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
 			return
 		}
 
-		page, pageSize := parsePaginationParams(c, 1, 10)
+		page, pageSize := parsePaginationParams(c, 1, 20)
 		offset := (page - 1) * pageSize
 
 		var totalCount int64
-		if err := db.Model(&models.URL{}).Count(&totalCount).Error; err != nil {
+		if err := db.Model(&models.CrawlRun{}).Where("url_id = ?", id).Count(&totalCount).Error; err != nil {
 			handleError(c, err)
 			return
 		}
 
-		var urls []models.URL
-		if err := db.Order(sortBy + " " + order).Limit(pageSize).Offset(offset).Find(&urls).Error; err != nil {
+		var runs []models.CrawlRun
+		if err := db.Where("url_id = ?", id).Order("started_at DESC").Limit(pageSize).Offset(offset).Find(&runs).Error; err != nil {
 			handleError(c, err)
 			return
 		}
 
-		response := make([]models.URLResponse, len(urls))
-		for i, u := range urls {
-			response[i] = urlToResponse(u)
-		}
-
 		c.JSON(http.StatusOK, gin.H{
 			"page":        page,
 			"page_size":   pageSize,
 			"total_count": totalCount,
-			"urls":        response,
+			"runs":        runs,
 		})
 	})
 
-	urlGroup.GET("/url/:id", func(c *gin.Context) {
+	// Download the HAR log of the most recent run that has one
+	urlGroup.GET("/url/:id/runs/latest/har", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil || id <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
 			return
 		}
 
-		var urlEntry models.URL
-		if err := db.Preload("BrokenLinksDetails").First(&urlEntry, id).Error; err != nil {
+		var run models.CrawlRun
+		if err := db.Joins("JOIN crawl_hars ON crawl_hars.crawl_run_id = crawl_runs.id").
+			Where("crawl_runs.url_id = ?", id).
+			Order("crawl_runs.started_at DESC").Order("crawl_runs.id DESC").
+			First(&run).Error; err != nil {
 			handleError(c, err)
 			return
 		}
+		serveHAR(c, db, &run)
+	})
 
-		c.JSON(http.StatusOK, urlToResponse(urlEntry))
+	urlGroup.GET("/url/:id/runs/:run_id/har", func(c *gin.Context) {
+		run, ok := findRun(c, db)
+		if !ok {
+			return
+		}
+		serveHAR(c, db, run)
 	})
 
 	urlGroup.DELETE("/url/:id", func(c *gin.Context) {
@@ -139,6 +169,54 @@ func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
 		c.JSON(http.StatusOK, gin.H{"message": "URL deleted successfully"})
 	})
 
+	urlGroup.PUT("/url/:id/credentials", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+			return
+		}
+
+		var creds credentials.Credentials
+		if err := c.ShouldBindJSON(&creds); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid credentials"})
+			return
+		}
+
+		var urlEntry models.URL
+		if err := db.First(&urlEntry, id).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		encrypted, err := encryptCredentials(&creds)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt credentials: " + err.Error()})
+			return
+		}
+
+		if err := db.Model(&urlEntry).Update("credentials", encrypted).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, urlToResponse(urlEntry))
+	})
+
+	urlGroup.DELETE("/url/:id/credentials", func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+			return
+		}
+
+		if err := db.Model(&models.URL{}).Where("id = ?", id).Update("credentials", "").Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Credentials removed successfully"})
+	})
+
 	urlGroup.POST("/crawl/:id", func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
@@ -158,7 +236,7 @@ func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
 			return
 		}
 
-		if err := analyzer.CrawlURL(db, &urlEntry); err != nil {
+		if err := analyzer.CrawlURL(db, f, &urlEntry); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -166,3 +244,104 @@ func RegisterURLRoutes(r *gin.Engine, db *gorm.DB) {
 		c.JSON(http.StatusOK, urlToResponse(urlEntry))
 	})
 }
+
+// listURLs serves a page of URLs, filtered by the parameters of
+// parseURLFilters and sorted by sort_by and order. Pages are addressed with
+// page or, for large sets, with the opaque next_cursor of the previous page
+// passed as cursor (an empty cursor starts from the top). include_total
+// controls the total count, which defaults to off for cursor pagination.
+func listURLs(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sort, err := parseURLSort(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort_by or order parameter"})
+			return
+		}
+		filters, err := parseURLFilters(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, pageSize := parsePaginationParams(c, 1, 10)
+		cursor, useCursor := c.GetQuery("cursor")
+
+		includeTotal := !useCursor
+		if v := c.Query("include_total"); v != "" {
+			if includeTotal, err = strconv.ParseBool(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid include_total parameter"})
+				return
+			}
+		}
+
+		query := sort.apply(filters.apply(db.Preload("Tags"))).Limit(pageSize)
+		switch {
+		case useCursor && cursor != "":
+			if query, err = sort.applyCursor(db, query, cursor); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		case !useCursor:
+			query = query.Offset((page - 1) * pageSize)
+		}
+
+		var urls []models.URL
+		if err := query.Find(&urls).Error; err != nil {
+			handleError(c, err)
+			return
+		}
+
+		response := make([]models.URLResponse, len(urls))
+		for i, u := range urls {
+			response[i] = urlToResponse(u)
+		}
+		result := gin.H{
+			"page_size":   pageSize,
+			"urls":        response,
+			"next_cursor": nil,
+		}
+		if !useCursor {
+			result["page"] = page
+		}
+
+		// A short page is the last one
+		if len(urls) == pageSize {
+			next, err := sort.encodeCursor(db, urls[len(urls)-1])
+			if err != nil {
+				handleError(c, err)
+				return
+			}
+			result["next_cursor"] = next
+		}
+
+		if includeTotal {
+			var totalCount int64
+			if err := filters.apply(db.Model(&models.URL{})).Count(&totalCount).Error; err != nil {
+				handleError(c, err)
+				return
+			}
+			result["total_count"] = totalCount
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// serveHAR sends the HAR log of a run as a download
+func serveHAR(c *gin.Context, db *gorm.DB, run *models.CrawlRun) {
+	var log models.CrawlHAR
+	if err := db.Where("crawl_run_id = ?", run.ID).First(&log).Error; err != nil {
+		handleError(c, err)
+		return
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(log.Data))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer zr.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="url-%d-run-%d.har"`, run.URLID, run.ID))
+	c.DataFromReader(http.StatusOK, -1, "application/json", zr, nil)
+}
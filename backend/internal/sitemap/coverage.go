@@ -0,0 +1,75 @@
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+	"github.com/UmutAkturk14/web-crawler/backend/internal/linkcheck"
+)
+
+// LinkedPages returns the pages on the same host that pageURL links to
+func LinkedPages(ctx context.Context, f *fetcher.Fetcher, pageURL string) ([]string, error) {
+	body, finalURL, err := get(ctx, f, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []string
+	for _, res := range linkcheck.ExtractAllLinks(doc, finalURL) {
+		if res.Type == linkcheck.ResourceAnchor && SameSite(res.URL, finalURL) {
+			pages = append(pages, res.URL)
+		}
+	}
+	return pages, nil
+}
+
+// Missing returns the pages that aren't listed in the sitemap entries, each once
+func Missing(entries []Entry, pages []string) []string {
+	listed := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		listed[Normalize(e.URL)] = true
+	}
+
+	var missing []string
+	for _, page := range pages {
+		key := Normalize(page)
+		if !listed[key] {
+			listed[key] = true
+			missing = append(missing, page)
+		}
+	}
+	return missing
+}
+
+// SameSite reports whether two URLs are on the same host, ignoring a leading www.
+func SameSite(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	host := func(u *url.URL) string { return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") }
+	return host(ua) == host(ub)
+}
+
+// Normalize makes URLs comparable: the scheme and host are lowercased and the
+// fragment and trailing slash dropped
+func Normalize(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
@@ -0,0 +1,226 @@
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/UmutAkturk14/web-crawler/backend/internal/fetcher"
+)
+
+// Limits keep a huge or recursive sitemap from running away
+const (
+	MaxSitemaps = 100
+	MaxEntries  = 50000
+	maxDepth    = 3
+)
+
+// Entry is a page listed in a sitemap
+type Entry struct {
+	URL     string
+	LastMod *time.Time
+}
+
+// FetchError is a sitemap that couldn't be read
+type FetchError struct {
+	URL    string `json:"url"`
+	Status string `json:"status"`
+}
+
+// Redirect is a sitemap that redirected elsewhere
+type Redirect struct {
+	URL      string `json:"url"`
+	FinalURL string `json:"final_url"`
+}
+
+// Result is everything read from a set of sitemaps
+type Result struct {
+	Sitemaps  []string
+	Entries   []Entry
+	Errors    []FetchError
+	Redirects []Redirect
+	Truncated bool
+}
+
+// sitemapDoc matches both <urlset> and <sitemapindex>, whatever their namespace
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// lastModLayouts are the W3C datetime forms allowed in <lastmod>
+var lastModLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// Discover returns the sitemaps of a site. A URL that already points to a
+// sitemap is returned as is; otherwise the Sitemap: lines of robots.txt are
+// used, falling back to /sitemap.xml.
+func Discover(ctx context.Context, f *fetcher.Fetcher, target string) ([]string, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid site URL %q", target)
+	}
+	if path := strings.ToLower(parsed.Path); strings.HasSuffix(path, ".xml") || strings.HasSuffix(path, ".xml.gz") {
+		return []string{target}, nil
+	}
+
+	root := &url.URL{Scheme: parsed.Scheme, Host: parsed.Host}
+	robotsURL := root.ResolveReference(&url.URL{Path: "/robots.txt"}).String()
+	if body, _, err := get(ctx, f, robotsURL); err == nil {
+		if sitemaps := parseRobots(body); len(sitemaps) > 0 {
+			return sitemaps, nil
+		}
+	}
+
+	return []string{root.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}, nil
+}
+
+// Load reads the given sitemaps and every sitemap their indexes point to
+func Load(ctx context.Context, f *fetcher.Fetcher, sitemapURLs []string) Result {
+	var result Result
+	seen := make(map[string]bool)
+
+	var load func(sitemapURL string, depth int)
+	load = func(sitemapURL string, depth int) {
+		if seen[sitemapURL] || result.Truncated {
+			return
+		}
+		if len(result.Sitemaps) >= MaxSitemaps {
+			result.Truncated = true
+			return
+		}
+		seen[sitemapURL] = true
+		result.Sitemaps = append(result.Sitemaps, sitemapURL)
+
+		body, finalURL, err := get(ctx, f, sitemapURL)
+		if finalURL != "" && finalURL != sitemapURL {
+			result.Redirects = append(result.Redirects, Redirect{URL: sitemapURL, FinalURL: finalURL})
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, FetchError{URL: sitemapURL, Status: err.Error()})
+			return
+		}
+
+		doc, err := parse(body)
+		if err != nil {
+			result.Errors = append(result.Errors, FetchError{URL: sitemapURL, Status: err.Error()})
+			return
+		}
+
+		for _, child := range doc.Sitemaps {
+			if depth >= maxDepth {
+				result.Errors = append(result.Errors, FetchError{URL: child.Loc, Status: "sitemap index nested too deeply"})
+				continue
+			}
+			load(strings.TrimSpace(child.Loc), depth+1)
+		}
+		for _, u := range doc.URLs {
+			if len(result.Entries) >= MaxEntries {
+				result.Truncated = true
+				return
+			}
+			loc := strings.TrimSpace(u.Loc)
+			if loc == "" {
+				continue
+			}
+			result.Entries = append(result.Entries, Entry{URL: loc, LastMod: parseLastMod(u.LastMod)})
+		}
+	}
+
+	for _, sitemapURL := range sitemapURLs {
+		load(sitemapURL, 0)
+	}
+	return result
+}
+
+// get fetches a URL and returns its body and the URL it ended up at
+func get(ctx context.Context, f *fetcher.Fetcher, target string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	finalURL := resp.Request.URL.String()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, finalURL, fmt.Errorf("%s", resp.Status)
+	}
+	body, err := f.ReadBody(resp)
+	return body, finalURL, err
+}
+
+// parse decodes a sitemap, decompressing .xml.gz files served without a Content-Encoding
+func parse(body []byte) (sitemapDoc, error) {
+	var doc sitemapDoc
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return doc, fmt.Errorf("invalid gzip sitemap: %w", err)
+		}
+		defer zr.Close()
+		if body, err = io.ReadAll(io.LimitReader(zr, 50<<20)); err != nil {
+			return doc, fmt.Errorf("invalid gzip sitemap: %w", err)
+		}
+	}
+
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		return doc, fmt.Errorf("invalid sitemap XML: %w", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return doc, fmt.Errorf("unexpected root element <%s>", doc.XMLName.Local)
+	}
+	return doc, nil
+}
+
+// parseRobots returns the Sitemap: entries of a robots.txt file
+func parseRobots(body []byte) []string {
+	var sitemaps []string
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+			if value = strings.TrimSpace(value); value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	return sitemaps
+}
+
+func parseLastMod(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range lastModLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
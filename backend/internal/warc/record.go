@@ -0,0 +1,144 @@
+package warc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Version is the WARC format written
+const Version = "WARC/1.1"
+
+// Record types
+const (
+	TypeWarcinfo = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+)
+
+// dateLayout is a W3C-ISO8601 date in UTC with the sub-second precision WARC 1.1 allows
+const dateLayout = "2006-01-02T15:04:05.000000Z"
+
+// Record is a single WARC record
+type Record struct {
+	Type          string
+	ID            string // <urn:uuid:...>
+	Date          time.Time
+	TargetURI     string
+	ConcurrentTo  string
+	IPAddress     string
+	Filename      string // warcinfo records only
+	ContentType   string
+	PayloadDigest string
+	// Truncated is the reason the payload is incomplete: length, time, disconnect or unspecified
+	Truncated string
+	Block     []byte
+}
+
+// NewRecordID returns a new record ID in its angle-bracketed URN form
+func NewRecordID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Digest returns the labelled SHA-1 digest of data as used by WARC-Block-Digest and WARC-Payload-Digest
+func Digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// NewRequestRecord records an HTTP request as it was sent
+func NewRequestRecord(req *http.Request, date time.Time) *Record {
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "%s %s %s\r\n", req.Method, req.URL.RequestURI(), protoOrDefault(req.Proto))
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&block, "Host: %s\r\n", host)
+	req.Header.Write(&block)
+	block.WriteString("\r\n")
+
+	return &Record{
+		Type:        TypeRequest,
+		ID:          NewRecordID(),
+		Date:        date,
+		TargetURI:   req.URL.String(),
+		ContentType: "application/http;msgtype=request",
+		Block:       block.Bytes(),
+	}
+}
+
+// NewResponseRecord records an HTTP response and its body as received. The
+// transport has already removed any chunked encoding, so the body is the
+// payload. The headers are the ones the transport kept, which no longer
+// include Transfer-Encoding.
+func NewResponseRecord(resp *http.Response, body []byte, date time.Time) *Record {
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "%s %s\r\n", protoOrDefault(resp.Proto), statusText(resp))
+	resp.Header.Write(&block)
+	block.WriteString("\r\n")
+	block.Write(body)
+
+	return &Record{
+		Type:          TypeResponse,
+		ID:            NewRecordID(),
+		Date:          date,
+		TargetURI:     resp.Request.URL.String(),
+		ContentType:   "application/http;msgtype=response",
+		PayloadDigest: Digest(body),
+		Block:         block.Bytes(),
+	}
+}
+
+// marshal serializes the record, without compression
+func (r *Record) marshal() []byte {
+	var b bytes.Buffer
+	b.WriteString(Version + "\r\n")
+	header := func(name, value string) {
+		if value != "" {
+			b.WriteString(name + ": " + value + "\r\n")
+		}
+	}
+	header("WARC-Type", r.Type)
+	header("WARC-Record-ID", r.ID)
+	header("WARC-Date", r.Date.UTC().Format(dateLayout))
+	header("WARC-Filename", r.Filename)
+	header("WARC-Target-URI", r.TargetURI)
+	header("WARC-Concurrent-To", r.ConcurrentTo)
+	header("WARC-IP-Address", r.IPAddress)
+	header("WARC-Block-Digest", Digest(r.Block))
+	header("WARC-Payload-Digest", r.PayloadDigest)
+	header("WARC-Truncated", r.Truncated)
+	header("Content-Type", r.ContentType)
+	header("Content-Length", strconv.Itoa(len(r.Block)))
+	b.WriteString("\r\n")
+	b.Write(r.Block)
+	b.WriteString("\r\n\r\n")
+	return b.Bytes()
+}
+
+func protoOrDefault(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+// statusText is the status code and reason, e.g. "200 OK"
+func statusText(resp *http.Response) string {
+	if resp.Status != "" {
+		return resp.Status
+	}
+	return strconv.Itoa(resp.StatusCode) + " " + http.StatusText(resp.StatusCode)
+}
@@ -0,0 +1,255 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSize is the size at which a WARC file is rotated, the 1 GB the WARC standard suggests
+const DefaultMaxSize = 1 << 30
+
+// Config configures where and how WARC files are written
+type Config struct {
+	Dir string
+	// Prefix starts every file name
+	Prefix string
+	// MaxSize rotates to a new file once a file reaches it. Records of one
+	// Write call always share a file, so files may grow slightly larger.
+	MaxSize int64
+	// Software is recorded in the warcinfo record of each file
+	Software string
+}
+
+// Location is where a record was written: its gzip member within a file
+type Location struct {
+	Filename string
+	Offset   int64
+	Length   int64
+}
+
+// Writer appends records as separate gzip members to size-rotated WARC files.
+// It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	config Config
+	file   *os.File
+	name   string
+	size   int64
+	serial int
+}
+
+// NewWriter creates the directory if needed. Files are only created once records are written.
+func NewWriter(config Config) (*Writer, error) {
+	if config.Dir == "" {
+		return nil, errors.New("warc: no directory configured")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "crawl"
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxSize
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("warc: %w", err)
+	}
+	return &Writer{config: config}, nil
+}
+
+// Dir is the directory the files are written to
+func (w *Writer) Dir() string {
+	return w.config.Dir
+}
+
+// Write appends the records to the current file, rotating it first if it's full
+func (w *Writer) Write(records ...*Record) ([]Location, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil || w.size >= w.config.MaxSize {
+		if err := w.rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	locations := make([]Location, 0, len(records))
+	for _, r := range records {
+		loc, err := w.append(r)
+		if err != nil {
+			return locations, err
+		}
+		locations = append(locations, loc)
+	}
+	return locations, nil
+}
+
+// Close closes the current file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate closes the current file and starts the next one with a warcinfo record
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("warc: %w", err)
+		}
+		w.file = nil
+	}
+
+	for {
+		w.serial++
+		name := fmt.Sprintf("%s-%s-%05d.warc.gz", w.config.Prefix, time.Now().UTC().Format("20060102150405"), w.serial)
+		file, err := os.OpenFile(filepath.Join(w.config.Dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("warc: %w", err)
+		}
+		w.file, w.name, w.size = file, name, 0
+		break
+	}
+
+	fields := "format: WARC File Format 1.1\r\n" +
+		"conformsTo: http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/\r\n"
+	if w.config.Software != "" {
+		fields = "software: " + w.config.Software + "\r\n" + fields
+	}
+	_, err := w.append(&Record{
+		Type:        TypeWarcinfo,
+		ID:          NewRecordID(),
+		Date:        time.Now(),
+		Filename:    w.name,
+		ContentType: "application/warc-fields",
+		Block:       []byte(fields),
+	})
+	return err
+}
+
+// append writes one record as its own gzip member so it can be read back from its offset
+func (w *Writer) append(r *Record) (Location, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(r.marshal()); err != nil {
+		return Location{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Location{}, err
+	}
+
+	loc := Location{Filename: w.name, Offset: w.size, Length: int64(buf.Len())}
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+	if err != nil {
+		return Location{}, fmt.Errorf("warc: %w", err)
+	}
+	return loc, nil
+}
+
+// ReadRecord reads the record at loc from a file in dir
+func ReadRecord(dir string, loc Location) (*Record, error) {
+	data, err := ReadRaw(dir, loc)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("warc: invalid record: %w", err)
+	}
+	defer zr.Close()
+
+	tp := textproto.NewReader(bufio.NewReader(zr))
+	version, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("warc: invalid record: %w", err)
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return nil, fmt.Errorf("warc: invalid record version %q", version)
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("warc: invalid record header: %w", err)
+	}
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return nil, errors.New("warc: invalid record Content-Length")
+	}
+	block := make([]byte, length)
+	if _, err := io.ReadFull(tp.R, block); err != nil {
+		return nil, fmt.Errorf("warc: truncated record: %w", err)
+	}
+
+	r := &Record{
+		Type:          header.Get("WARC-Type"),
+		ID:            header.Get("WARC-Record-ID"),
+		TargetURI:     header.Get("WARC-Target-URI"),
+		ConcurrentTo:  header.Get("WARC-Concurrent-To"),
+		IPAddress:     header.Get("WARC-IP-Address"),
+		Filename:      header.Get("WARC-Filename"),
+		ContentType:   header.Get("Content-Type"),
+		PayloadDigest: header.Get("WARC-Payload-Digest"),
+		Truncated:     header.Get("WARC-Truncated"),
+		Block:         block,
+	}
+	r.Date, _ = time.Parse(time.RFC3339Nano, header.Get("WARC-Date"))
+	return r, nil
+}
+
+// ReadRaw returns the compressed bytes of the record at loc, a valid WARC file on its own
+func ReadRaw(dir string, loc Location) ([]byte, error) {
+	// Index entries only ever name files directly in dir
+	if loc.Filename != filepath.Base(loc.Filename) {
+		return nil, fmt.Errorf("warc: invalid file name %q", loc.Filename)
+	}
+	file, err := os.Open(filepath.Join(dir, loc.Filename))
+	if err != nil {
+		return nil, fmt.Errorf("warc: %w", err)
+	}
+	defer file.Close()
+
+	data := make([]byte, loc.Length)
+	if _, err := file.ReadAt(data, loc.Offset); err != nil {
+		return nil, fmt.Errorf("warc: %w", err)
+	}
+	return data, nil
+}
+
+// HTTPResponse splits the block of a response record into its status line,
+// headers and body. The headers are the archived ones, so Content-Length may
+// not match a truncated body.
+func (r *Record) HTTPResponse() (status string, header http.Header, body []byte, err error) {
+	if r.Type != TypeResponse {
+		return "", nil, nil, fmt.Errorf("warc: %s record has no HTTP response", r.Type)
+	}
+	br := bufio.NewReader(bytes.NewReader(r.Block))
+	tp := textproto.NewReader(br)
+	status, err = tp.ReadLine()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("warc: invalid HTTP response: %w", err)
+	}
+	mime, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("warc: invalid HTTP response header: %w", err)
+	}
+	body, err = io.ReadAll(br)
+	return status, http.Header(mime), body, err
+}
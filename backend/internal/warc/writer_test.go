@@ -0,0 +1,163 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testExchange(t *testing.T) (*http.Request, *http.Response) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/page?q=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "test")
+	resp := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Request:    req,
+	}
+	return req, resp
+}
+
+func TestWriteReadRecord(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Dir: dir, Prefix: "test", Software: "tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	date := time.Date(2024, 5, 1, 12, 0, 0, 123000000, time.UTC)
+	body := []byte("<html><body>Hello</body></html>")
+	req, resp := testExchange(t)
+	request := NewRequestRecord(req, date)
+	response := NewResponseRecord(resp, body, date)
+	response.ConcurrentTo = request.ID
+	response.IPAddress = "192.0.2.1"
+	response.Truncated = "length"
+
+	locations, err := w.Write(request, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations) != 2 || locations[0].Filename != locations[1].Filename {
+		t.Fatalf("locations = %+v, want two records in one file", locations)
+	}
+	if locations[0].Offset == 0 {
+		t.Errorf("request offset = 0, want it after the warcinfo record")
+	}
+
+	got, err := ReadRecord(dir, locations[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != TypeResponse || got.ID != response.ID || got.ConcurrentTo != request.ID ||
+		got.TargetURI != "https://example.com/page?q=1" || got.IPAddress != "192.0.2.1" ||
+		got.Truncated != "length" || got.PayloadDigest != Digest(body) || !got.Date.Equal(date) {
+		t.Errorf("read %+v, want the written response record", got)
+	}
+	if !bytes.Equal(got.Block, response.Block) {
+		t.Errorf("block = %q, want %q", got.Block, response.Block)
+	}
+
+	status, header, gotBody, err := got.HTTPResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "HTTP/1.1 200 OK" || header.Get("Content-Type") != "text/html; charset=utf-8" || !bytes.Equal(gotBody, body) {
+		t.Errorf("HTTPResponse = %q, %v, %q", status, header, gotBody)
+	}
+
+	got, err = ReadRecord(dir, locations[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != TypeRequest || !strings.HasPrefix(string(got.Block), "GET /page?q=1 HTTP/1.1\r\nHost: example.com\r\n") {
+		t.Errorf("request record = %s %q", got.Type, got.Block)
+	}
+	if _, _, _, err := got.HTTPResponse(); err == nil {
+		t.Error("HTTPResponse of a request record succeeded")
+	}
+}
+
+func TestWriterStartsFilesWithWarcinfo(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Dir: dir, Software: "tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, resp := testExchange(t)
+	locations, err := w.Write(NewResponseRecord(resp, []byte("x"), time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ReadRecord(dir, Location{Filename: locations[0].Filename, Offset: 0, Length: locations[0].Offset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Type != TypeWarcinfo || info.Filename != locations[0].Filename || !strings.Contains(string(info.Block), "software: tests\r\n") {
+		t.Errorf("first record = %+v, want warcinfo", info)
+	}
+
+	// Every record is its own gzip member, so the whole file reads as one stream
+	file, err := os.Open(filepath.Join(dir, locations[0].Filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(all), "WARC/1.1\r\n"); n != 2 {
+		t.Errorf("file holds %d records, want 2", n)
+	}
+}
+
+func TestWriterRotates(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWriter(Config{Dir: dir, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	_, resp := testExchange(t)
+	files := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		locations, err := w.Write(NewResponseRecord(resp, []byte("x"), time.Now()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[locations[0].Filename] = true
+	}
+	if len(files) != 3 {
+		t.Errorf("wrote to %d files, want 3", len(files))
+	}
+}
+
+func TestReadRawRejectsPaths(t *testing.T) {
+	for _, name := range []string{"../secret.warc.gz", "sub/file.warc.gz"} {
+		if _, err := ReadRaw(t.TempDir(), Location{Filename: name, Length: 1}); err == nil {
+			t.Errorf("ReadRaw(%q) succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// A WARC record archived during a crawl run
+export interface ArchiveRecord {
+  id: number;
+  crawl_run_id: number;
+  record_id: string;
+  type: "request" | "response";
+  source: "page" | "link";
+  target_uri: string;
+  status_code?: number;
+  payload_digest?: string;
+  truncated: boolean;
+  filename: string;
+  offset: number;
+  length: number;
+  date: string;
+}
+
+export interface RunArchive {
+  run_id: number;
+  records: ArchiveRecord[];
+}
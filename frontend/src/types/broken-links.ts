@@ -2,4 +2,6 @@ export interface BrokenLink {
   id: number;
   url_id: number;
   link: string;
+  resource_type: string;
+  status: string;
 }
@@ -0,0 +1,21 @@
+export interface BulkItemResult {
+  id?: number;
+  url?: string;
+  status:
+    | "created"
+    | "exists"
+    | "invalid"
+    | "deleted"
+    | "not_found"
+    | "done"
+    | "failed"
+    | "cancelled"
+    | "tagged";
+  error?: string;
+}
+
+export interface BulkResponse {
+  results: BulkItemResult[];
+  succeeded: number;
+  failed: number;
+}
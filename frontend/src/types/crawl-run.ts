@@ -0,0 +1,38 @@
+export interface CrawlRun {
+  id: number;
+  status: "running" | "done" | "failed";
+  http_status?: number;
+  failure_reason?: string;
+  started_at: string;
+  finished_at?: string;
+  final_url?: string;
+  snapshot_key?: string;
+  snapshot_content_type?: string;
+  dns_ms: number;
+  connect_ms: number;
+  tls_ms: number;
+  ttfb_ms: number;
+  download_ms: number;
+  total_ms: number;
+  html_size: number;
+  transfer_size: number;
+  content_encoding?: string;
+  script_count: number;
+  script_bytes: number;
+  stylesheet_count: number;
+  stylesheet_bytes: number;
+  image_count: number;
+  image_bytes: number;
+  word_count: number;
+  text_ratio: number;
+  reading_ease: number;
+  reading_grade: number;
+  detected_language?: string;
+}
+
+export interface CrawlRunPage {
+  page: number;
+  page_size: number;
+  total_count: number;
+  runs: CrawlRun[];
+}
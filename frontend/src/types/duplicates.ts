@@ -0,0 +1,17 @@
+export interface DuplicatePage {
+  ID: number;
+  url: string;
+  title: string;
+  distance: number;
+}
+
+export interface DuplicateCluster {
+  host: string;
+  exact: boolean;
+  pages: DuplicatePage[];
+}
+
+export interface DuplicateClusters {
+  max_distance: number;
+  clusters: DuplicateCluster[];
+}
@@ -0,0 +1,10 @@
+export type ExportFormat = "csv" | "ndjson" | "xlsx";
+
+// A row of GET /urls/export/broken-links
+export interface BrokenLinkExport {
+  url_id: number;
+  url: string;
+  link: string;
+  resource_type: string;
+  status: string;
+}
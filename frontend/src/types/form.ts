@@ -0,0 +1,20 @@
+export interface Form {
+  position: number;
+  kind:
+    | "login"
+    | "signup"
+    | "password_change"
+    | "search"
+    | "contact"
+    | "newsletter"
+    | "payment"
+    | "other";
+  method: string;
+  action: string;
+  action_https: boolean;
+  has_csrf_token: boolean;
+  autocomplete?: string;
+  field_autocomplete?: string[];
+  password_fields: number;
+  selector: string;
+}
@@ -0,0 +1,5 @@
+export interface Heading {
+  position: number;
+  level: number;
+  text: string;
+}
@@ -0,0 +1,10 @@
+export interface Issue {
+  ID: number;
+  url_id: number;
+  category: string;
+  severity: "error" | "warning" | "info";
+  code: string;
+  message: string;
+  target?: string;
+  selector?: string;
+}
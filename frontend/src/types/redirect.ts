@@ -0,0 +1,16 @@
+export interface RedirectHop {
+  position: number;
+  url: string;
+  status_code: number;
+  location: string;
+}
+
+export interface RedirectChain {
+  ID: number;
+  url_id: number;
+  source: string;
+  resource_type: string;
+  final_url: string;
+  loop: boolean;
+  hops: RedirectHop[];
+}
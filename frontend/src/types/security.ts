@@ -0,0 +1,25 @@
+export interface SecurityHeader {
+  name: string;
+  value?: string;
+  grade: "good" | "weak" | "missing";
+  note?: string;
+}
+
+export interface CookieFlags {
+  name: string;
+  secure: boolean;
+  http_only: boolean;
+  same_site?: "Lax" | "Strict" | "None";
+}
+
+export interface SecurityReport {
+  grade: "A" | "B" | "C" | "D" | "F";
+  headers: SecurityHeader[];
+  cookies: CookieFlags[] | null;
+  tls_version?: string;
+  tls_cipher?: string;
+  cert_subject?: string;
+  cert_issuer?: string;
+  cert_sans?: string[];
+  cert_not_after?: string;
+}
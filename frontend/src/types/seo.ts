@@ -0,0 +1,15 @@
+export interface HreflangAlternate {
+  lang: string;
+  url: string;
+}
+
+export interface SeoMetadata {
+  meta_description: string;
+  meta_robots: string;
+  canonical: string;
+  viewport: string;
+  lang: string;
+  hreflang: HreflangAlternate[] | null;
+  open_graph: Record<string, string> | null;
+  twitter_card: Record<string, string> | null;
+}
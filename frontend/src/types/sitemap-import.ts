@@ -0,0 +1,27 @@
+export interface SitemapUrlResult {
+  url: string;
+  status?: string;
+  final_url?: string;
+}
+
+export interface SitemapImportRequest {
+  url: string;
+  modified_since?: string;
+  check_urls?: boolean;
+}
+
+export interface SitemapImportResult {
+  sitemaps: string[];
+  sitemap_errors: { url: string; status: string }[] | null;
+  sitemap_redirects: { url: string; final_url: string }[] | null;
+  truncated: boolean;
+  found: number;
+  created: number;
+  existing: number;
+  skipped: SitemapUrlResult[] | null;
+  invalid_urls: SitemapUrlResult[] | null;
+  checked: number;
+  url_errors: SitemapUrlResult[] | null;
+  url_redirects: SitemapUrlResult[] | null;
+  missing_from_sitemap: string[] | null;
+}
@@ -0,0 +1,8 @@
+import type { UrlReport } from "./url-report";
+
+// Response of POST /url/:id/runs/:run_id/reanalyze
+export interface SnapshotReanalysis {
+  run_id: number;
+  snapshot_key: string;
+  analysis: UrlReport;
+}
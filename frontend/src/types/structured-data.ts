@@ -0,0 +1,5 @@
+export interface StructuredData {
+  format: "json-ld" | "microdata" | "rdfa";
+  type: string;
+  properties: Record<string, unknown>;
+}
@@ -0,0 +1,11 @@
+import type { UrlReport } from "./url-report";
+
+// A page of GET /urls. page is only set for offset pagination and
+// total_count only when it was counted (include_total).
+export interface UrlPage {
+  page?: number;
+  page_size: number;
+  total_count?: number;
+  next_cursor: string | null;
+  urls: UrlReport[];
+}
@@ -1,16 +1,41 @@
 import type { BrokenLink } from "./broken-links";
+import type { Heading } from "./heading";
+import type { Form } from "./form";
+import type { Issue } from "./issue";
+import type { RedirectChain } from "./redirect";
+import type { SeoMetadata } from "./seo";
+import type { SecurityReport } from "./security";
+import type { StructuredData } from "./structured-data";
 
 export interface UrlReport {
   ID: number;
   url: string;
-  status: "done" | "error" | "pending" | "running" | "queued";
+  status: "done" | "error" | "failed" | "pending" | "running" | "queued";
   title: string;
   html_version: string;
   internal_links: number;
   external_links: number;
   broken_links: number;
   has_login_form: boolean;
+  mixed_content_count: number;
+  mixed_content_active: number;
+  mixed_content_passive: number;
+  has_credentials: boolean;
   created_at: string;
+  final_url?: string;
+  http_status?: number;
+  failure_reason?: string;
+  content_type?: string;
+  content_length: number;
+  charset?: string;
+  word_count: number;
+  text_ratio: number;
+  reading_ease: number;
+  reading_grade: number;
+  detected_language?: string;
+  content_hash?: string;
+  sitemap_lastmod?: string;
+  tags: string[];
   h1_count: number;
   h2_count: number;
   h3_count: number;
@@ -19,4 +44,12 @@ export interface UrlReport {
   h6_count: number;
 
   broken_links_details?: BrokenLink[];
+  broken_links_by_type?: Record<string, number>;
+  issues?: Issue[];
+  redirects?: RedirectChain[];
+  seo?: SeoMetadata;
+  structured_data?: Record<string, StructuredData[]>;
+  headings?: Heading[];
+  forms?: Form[];
+  security?: SecurityReport;
 }